@@ -0,0 +1,253 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/realency/arke/pkg/bitmap"
+	"github.com/realency/arke/pkg/bits"
+	"github.com/realency/arke/pkg/display"
+	"github.com/realency/arke/pkg/font"
+	"github.com/realency/arke/pkg/max7219"
+)
+
+func runText(d *device, args []string) error {
+	fs := flag.NewFlagSet("text", flag.ContinueOnError)
+	scroll := fs.Bool("scroll", false, "scroll the text from right to left")
+	speed := fs.Float64("speed", 20, "scrolling speed in columns per second")
+	repeat := fs.Int("repeat", 0, "number of times to scroll the text, or 0 to scroll until interrupted")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("expected a single text argument")
+	}
+	if *speed <= 0 {
+		return errors.New("speed must be positive")
+	}
+
+	text := font.Render(font.Standard, fs.Arg(0))
+	vp, err := d.open()
+	if err != nil {
+		return err
+	}
+	h, w := vp.Size()
+
+	if !*scroll {
+		canvas := display.NewCanvas(h, w)
+		canvas.Write(text, 0, 0)
+		vp.Attach(canvas, 0, 0)
+		return d.flush()
+	}
+
+	// The text is written to a canvas with a viewport's width of blank space either side, so that it scrolls in from and out to a blank display
+	_, tw := text.Size()
+	canvas := display.NewCanvas(h, tw+2*w)
+	canvas.Write(text, 0, w)
+	vp.Attach(canvas, 0, 0)
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / *speed))
+	defer ticker.Stop()
+
+	for i := 0; *repeat == 0 || i < *repeat; i++ {
+		for col := 0; col <= tw+w; col++ {
+			select {
+			case <-interrupt:
+				vp.Detach()
+				return d.flush()
+			case <-ticker.C:
+				vp.Locate(0, col)
+			}
+		}
+	}
+	return d.flush()
+}
+
+// Digits three pixels wide and five high, one row of bits per pixel row, most significant bit leftmost.
+var smallDigits = [10][5]byte{
+	{7, 5, 5, 5, 7}, // 0
+	{2, 6, 2, 2, 7}, // 1
+	{7, 1, 7, 4, 7}, // 2
+	{7, 1, 3, 1, 7}, // 3
+	{5, 5, 7, 1, 1}, // 4
+	{7, 4, 7, 1, 7}, // 5
+	{7, 4, 7, 5, 7}, // 6
+	{7, 1, 1, 2, 2}, // 7
+	{7, 5, 7, 5, 7}, // 8
+	{7, 5, 7, 1, 7}, // 9
+}
+
+// renderSmallDigits renders a string of decimal digits in a font narrow enough to fit two digits on a single module.
+func renderSmallDigits(s string) *bits.Matrix {
+	result := bits.NewMatrix(8, len(s)*4-1)
+	for i, r := range s {
+		glyph := smallDigits[r-'0']
+		for row, pattern := range glyph {
+			for col := 0; col < 3; col++ {
+				if pattern&(4>>col) != 0 {
+					result.Set(row+1, i*4+col, true)
+				}
+			}
+		}
+	}
+	return result
+}
+
+func runImage(d *device, args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected a single file argument")
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	img, err := bitmap.Decode(f)
+	if err != nil {
+		return err
+	}
+	if h, w := img.Size(); h == 0 || w == 0 {
+		return errors.New("image is empty")
+	}
+
+	vp, err := d.open()
+	if err != nil {
+		return err
+	}
+	h, w := vp.Size()
+	canvas := display.NewCanvas(h, w)
+	canvas.Write(img, 0, 0)
+	vp.Attach(canvas, 0, 0)
+	return d.flush()
+}
+
+func runClear(d *device, args []string) error {
+	if len(args) != 0 {
+		return errors.New("unexpected arguments")
+	}
+
+	vp, err := d.open()
+	if err != nil {
+		return err
+	}
+	h, w := vp.Size()
+	vp.Attach(display.NewCanvas(h, w), 0, 0)
+	return d.flush()
+}
+
+func runBrightness(d *device, args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected a single brightness argument")
+	}
+	b, err := strconv.Atoi(args[0])
+	if err != nil || b < 0 || b > 15 {
+		return fmt.Errorf("brightness must be in the range 0..15")
+	}
+
+	if d.cfg.emulate {
+		fmt.Printf("brightness set to %d\n", b)
+		return nil
+	}
+
+	// Written directly to the bus, rather than through a viewport, so that the content of the display is left intact
+	d.broadcast(max7219.IntensityRegister, max7219.Intensity(b))
+	return d.flush()
+}
+
+func runTest(d *device, args []string) error {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	duration := fs.Duration("duration", 0, "how long to light the display, or 0 to wait until interrupted")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	var timeout <-chan time.Time
+	if *duration > 0 {
+		timeout = time.After(*duration)
+	}
+
+	if d.cfg.emulate {
+		vp, err := d.open()
+		if err != nil {
+			return err
+		}
+		h, w := vp.Size()
+		all := bits.NewMatrix(h, w)
+		all.Not()
+		canvas := display.NewCanvas(h, w)
+		canvas.Write(all, 0, 0)
+		vp.Attach(canvas, 0, 0)
+		select {
+		case <-interrupt:
+		case <-timeout:
+		}
+		vp.Detach()
+		return nil
+	}
+
+	d.broadcast(max7219.DisplayTestRegister, max7219.DisplayTest)
+	select {
+	case <-interrupt:
+	case <-timeout:
+	}
+	d.broadcast(max7219.DisplayTestRegister, max7219.NoDisplayTest)
+	return d.flush()
+}
+
+func runIdentify(d *device, args []string) error {
+	if len(args) != 0 {
+		return errors.New("unexpected arguments")
+	}
+
+	vp, err := d.open()
+	if err != nil {
+		return err
+	}
+	h, w := vp.Size()
+	canvas := display.NewCanvas(h, w)
+
+	// Modules are numbered by their position in the chain, so module zero is at the end given by the chain orientation
+	n := d.cfg.chainLength
+	for i := 0; i < n; i++ {
+		label := font.Render(font.Standard, strconv.Itoa(i))
+		if _, lw := label.Size(); lw > 8 {
+			label = renderSmallDigits(strconv.Itoa(i))
+		}
+		_, lw := label.Size()
+		if lw > 8 {
+			// Too many digits to fit a module, even in the small font, so show only the last of them
+			clipped := bits.NewMatrix(8, 8)
+			bits.Copy(label, 0, lw-8, clipped, 0, 0, 8, 8)
+			label, lw = clipped, 8
+		}
+		var row, col int
+		switch orientations[d.cfg.chainOrientation] {
+		case max7219.BlockZeroAtTop:
+			row, col = i*8, 0
+		case max7219.BlockZeroAtRight:
+			row, col = 0, (n-1-i)*8
+		case max7219.BlockZeroAtBottom:
+			row, col = (n-1-i)*8, 0
+		case max7219.BlockZeroAtLeft:
+			row, col = 0, i*8
+		}
+		if lw < 8 {
+			col += (8 - lw) / 2
+		}
+		canvas.Write(label, row, col)
+	}
+
+	vp.Attach(canvas, 0, 0)
+	return d.flush()
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/realency/arke/pkg/max7219"
+	"github.com/realency/arke/pkg/terminal"
+	"github.com/realency/arke/pkg/viewport"
+	"periph.io/x/conn/v3/physic"
+)
+
+// Time allowed for queued operations to reach the hardware before the process exits.
+const flushTimeout = 5 * time.Second
+
+type config struct {
+	device           string
+	speed            physic.Frequency
+	chainLength      int
+	blockOrientation string
+	chainOrientation string
+	brightness       int
+	emulate          bool
+}
+
+// device provides the display targeted by a command.
+// The viewport is built on first use, since building a MAX7219 viewport initialises, and so clears, the display.
+type device struct {
+	cfg      config
+	bus      max7219.Bus
+	viewPort viewport.ViewPort
+	emulated *terminal.ViewPort
+}
+
+var orientations = map[string]int{
+	"top":    0,
+	"right":  1,
+	"bottom": 2,
+	"left":   3,
+}
+
+func openDevice(cfg config) (*device, error) {
+	if cfg.chainLength < 1 {
+		return nil, fmt.Errorf("chain length must be at least 1")
+	}
+	if _, ok := orientations[cfg.blockOrientation]; !ok {
+		return nil, fmt.Errorf("unrecognised block orientation %q", cfg.blockOrientation)
+	}
+	if _, ok := orientations[cfg.chainOrientation]; !ok {
+		return nil, fmt.Errorf("unrecognised chain orientation %q", cfg.chainOrientation)
+	}
+	if cfg.brightness > 15 {
+		return nil, fmt.Errorf("brightness must be in the range 0..15")
+	}
+
+	d := &device{cfg: cfg}
+	if cfg.emulate {
+		return d, nil
+	}
+
+	// The emulator may take any shape, but the MAX7219 driver supports only one orientation so far
+	if orientations[cfg.blockOrientation] != max7219.DigitZeroAtBottom {
+		return nil, fmt.Errorf("block orientation %q is not supported: only bottom is currently supported", cfg.blockOrientation)
+	}
+	if orientations[cfg.chainOrientation] != max7219.BlockZeroAtRight {
+		return nil, fmt.Errorf("chain orientation %q is not supported: only right is currently supported", cfg.chainOrientation)
+	}
+
+	var err error
+	if d.bus, err = max7219.FromDeviceName(cfg.device).WithClockSpeed(cfg.speed).Build(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// size returns the size of the display in pixels.
+func (d *device) size() (height, width int) {
+	switch orientations[d.cfg.chainOrientation] {
+	case max7219.BlockZeroAtTop, max7219.BlockZeroAtBottom:
+		return d.cfg.chainLength * 8, 8
+	}
+	return 8, d.cfg.chainLength * 8
+}
+
+func (d *device) open() (viewport.ViewPort, error) {
+	if d.viewPort != nil {
+		return d.viewPort, nil
+	}
+
+	if d.cfg.emulate {
+		h, w := d.size()
+		vp, err := terminal.ToStdout().WithSize(h, w).WithColors(terminal.Red, terminal.DarkRed).Build()
+		if err != nil {
+			return nil, err
+		}
+		d.viewPort, d.emulated = vp, vp
+		return vp, nil
+	}
+
+	vp, err := max7219.FromBus(d.bus).
+		WithChainLength(d.cfg.chainLength).
+		WithOrientation(orientations[d.cfg.blockOrientation], orientations[d.cfg.chainOrientation]).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+	if d.cfg.brightness >= 0 {
+		vp.SetBrightness(byte(d.cfg.brightness))
+	}
+	d.viewPort = vp
+	return vp, nil
+}
+
+// broadcast writes the same value to a register of every chip in the chain.
+func (d *device) broadcast(reg max7219.Register, data byte) {
+	max7219.NewChain(d.bus, d.cfg.chainLength).Broadcast(reg, data)
+}
+
+// flush waits for queued operations to reach the display before the process exits.
+func (d *device) flush() error {
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+
+	if d.viewPort != nil {
+		if err := viewport.Flush(ctx, d.viewPort); err != nil {
+			return err
+		}
+	}
+	if d.bus != nil {
+		return max7219.FlushBus(ctx, d.bus)
+	}
+	return nil
+}
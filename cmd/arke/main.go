@@ -0,0 +1,91 @@
+// Command arke drives MAX7219 dot-matrix displays from the shell.
+//
+// Usage:
+//
+//	arke [flags] <command> [arguments]
+//
+// The commands are:
+//
+//	text        show static or scrolling text
+//	image       show a PBM, PNG or GIF image
+//	clear       blank the display
+//	brightness  set the brightness of the display, 0..15
+//	test        light every LED using the display-test register
+//	identify    show the index of each module in the chain
+//
+// With the -emulate flag, output is drawn on the terminal instead of being sent to the hardware.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/realency/arke/pkg/max7219"
+)
+
+type command struct {
+	name, args, help string
+	run              func(d *device, args []string) error
+}
+
+var commands = []command{
+	{"text", "[-scroll] [-speed cols/sec] [-repeat n] <text>", "show static or scrolling text", runText},
+	{"image", "<file>", "show a PBM, PNG or GIF image", runImage},
+	{"clear", "", "blank the display", runClear},
+	{"brightness", "<0..15>", "set the brightness of the display", runBrightness},
+	{"test", "[-duration d]", "light every LED using the display-test register", runTest},
+	{"identify", "", "show the index of each module in the chain", runIdentify},
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: arke [flags] <command> [arguments]\n\nCommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(out, "  %-11s %s\n", c.name, c.help)
+		if c.args != "" {
+			fmt.Fprintf(out, "  %-11s   arke %s %s\n", "", c.name, c.args)
+		}
+	}
+	fmt.Fprintf(out, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	var cfg config
+	flag.StringVar(&cfg.device, "device", "", "SPI device name, or empty for the default device")
+	cfg.speed = max7219.DefaultClockSpeed
+	flag.Var(&cfg.speed, "spi-speed", "SPI clock speed, such as 1MHz")
+	flag.IntVar(&cfg.chainLength, "chain", 4, "number of 8x8 modules in the chain")
+	flag.StringVar(&cfg.blockOrientation, "block-orientation", "bottom", "position of digit zero in each module: top, right, bottom or left")
+	flag.StringVar(&cfg.chainOrientation, "chain-orientation", "right", "position of module zero in the chain: top, right, bottom or left")
+	flag.IntVar(&cfg.brightness, "brightness", -1, "brightness of the display, 0..15, or -1 to leave unchanged")
+	flag.BoolVar(&cfg.emulate, "emulate", false, "draw on the terminal instead of driving hardware")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := flag.Arg(0)
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+		d, err := openDevice(cfg)
+		if err == nil {
+			err = c.run(d, flag.Args()[1:])
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "arke %s: %v\n", name, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "arke: unknown command %q\n", name)
+	usage()
+	os.Exit(2)
+}
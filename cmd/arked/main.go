@@ -0,0 +1,182 @@
+// Command arked is a display daemon, allowing several processes to share a MAX7219 display.
+//
+// The daemon owns the display, and accepts commands over a Unix socket and an HTTP/JSON API.
+// Clients lease named regions of the display, and draw text or pixels within them.
+// See package github.com/realency/arke/pkg/daemon for the protocol.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/realency/arke/pkg/config"
+	"github.com/realency/arke/pkg/daemon"
+	"github.com/realency/arke/pkg/display"
+	"github.com/realency/arke/pkg/max7219"
+	"github.com/realency/arke/pkg/terminal"
+	"github.com/realency/arke/pkg/viewport"
+)
+
+var orientations = map[string]int{
+	"top":    0,
+	"right":  1,
+	"bottom": 2,
+	"left":   3,
+}
+
+func main() {
+	device := flag.String("device", "", "SPI device name, or empty for the default device")
+	chainLength := flag.Int("chain", 4, "number of 8x8 modules in the chain")
+	blockOrientation := flag.String("block-orientation", "bottom", "position of digit zero in each module: top, right, bottom or left")
+	chainOrientation := flag.String("chain-orientation", "right", "position of module zero in the chain: top, right, bottom or left")
+	brightness := flag.Int("brightness", 4, "initial brightness of the display, 0..15")
+	emulate := flag.Bool("emulate", false, "draw on the terminal instead of driving hardware")
+	socket := flag.String("socket", "/tmp/arked.sock", "path of the Unix socket, or empty to disable")
+	httpAddr := flag.String("http", "localhost:7219", "address for the HTTP API, or empty to disable")
+	configPath := flag.String("config", "", "path of a hardware configuration file, overriding the display flags")
+	flag.Parse()
+
+	var canvas *display.Canvas
+	var vp viewport.ViewPort
+	var closer io.Closer
+	var err error
+	if *configPath != "" {
+		canvas, vp, closer, err = fromConfig(*configPath)
+	} else {
+		vp, err = openViewPort(*device, *chainLength, *blockOrientation, *chainOrientation, *brightness, *emulate)
+		if err == nil {
+			canvas = display.NewCanvas(vp.Size())
+			vp.Attach(canvas, 0, 0)
+			closer = viewPortCloser{vp}
+		}
+	}
+	if err != nil {
+		log.Fatalf("arked: %v", err)
+	}
+
+	err = serve(canvas, vp, *socket, *httpAddr)
+	closer.Close()
+	if err != nil {
+		log.Fatalf("arked: %v", err)
+	}
+}
+
+// Runs the daemon until the process is interrupted or terminated, or the HTTP server fails.
+// The daemon is closed, and the socket removed, before returning.
+func serve(canvas *display.Canvas, vp viewport.ViewPort, socket, httpAddr string) error {
+	d := daemon.New(canvas, vp)
+	defer d.Close()
+
+	if socket != "" {
+		// A socket left behind by a previous instance would prevent listening
+		if err := os.Remove(socket); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		l, err := net.Listen("unix", socket)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(socket)
+		go func() {
+			log.Println(d.Serve(l))
+		}()
+	}
+
+	failed := make(chan error, 1)
+	if httpAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/api/", d)
+		server := &http.Server{Addr: httpAddr, Handler: mux}
+		defer server.Close()
+		go func() {
+			if err := server.ListenAndServe(); err != http.ErrServerClosed {
+				failed <- err
+			}
+		}()
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	select {
+	case <-stop:
+		return nil
+	case err := <-failed:
+		return err
+	}
+}
+
+// Adapts a viewport to io.Closer.
+type viewPortCloser struct {
+	viewport.ViewPort
+}
+
+func (c viewPortCloser) Close() error {
+	return viewport.Close(c.ViewPort)
+}
+
+// Builds the system described by a configuration file.  The daemon manages the first canvas in the configuration,
+// and controls the brightness of the first display attached to it.  The whole system is closed by closing the
+// returned closer, including any displays attached to other canvases.
+func fromConfig(path string) (*display.Canvas, viewport.ViewPort, io.Closer, error) {
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(cfg.Canvases) == 0 {
+		return nil, nil, nil, errors.New("configuration has no canvases")
+	}
+	c := cfg.Canvases[0]
+	if len(c.ViewPorts) == 0 {
+		return nil, nil, nil, fmt.Errorf("canvas %q has no viewports", c.Name)
+	}
+
+	sys, err := cfg.Build()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return sys.Canvases[c.Name], sys.ViewPorts[c.ViewPorts[0].Display], sys, nil
+}
+
+func openViewPort(device string, chainLength int, blockOrientation, chainOrientation string, brightness int, emulate bool) (viewport.ViewPort, error) {
+	bo, ok := orientations[blockOrientation]
+	if !ok {
+		return nil, errors.New("unrecognised block orientation " + blockOrientation)
+	}
+	co, ok := orientations[chainOrientation]
+	if !ok {
+		return nil, errors.New("unrecognised chain orientation " + chainOrientation)
+	}
+	if brightness < 0 || brightness > 15 {
+		return nil, errors.New("brightness must be in the range 0..15")
+	}
+	// The emulator may take any shape, but the MAX7219 driver supports only one orientation so far
+	if !emulate && bo != max7219.DigitZeroAtBottom {
+		return nil, errors.New("block orientation " + blockOrientation + " is not supported: only bottom is currently supported")
+	}
+	if !emulate && co != max7219.BlockZeroAtRight {
+		return nil, errors.New("chain orientation " + chainOrientation + " is not supported: only right is currently supported")
+	}
+
+	if emulate {
+		h, w := 8, chainLength*8
+		if co == max7219.BlockZeroAtTop || co == max7219.BlockZeroAtBottom {
+			h, w = w, h
+		}
+		return terminal.ToStdout().WithSize(h, w).WithColors(terminal.Red, terminal.DarkRed).Build()
+	}
+
+	vp, err := max7219.FromDeviceName(device).WithChainLength(chainLength).WithOrientation(bo, co).Build()
+	if err != nil {
+		return nil, err
+	}
+	vp.SetBrightness(byte(brightness))
+	return vp, nil
+}
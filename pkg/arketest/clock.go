@@ -0,0 +1,83 @@
+package arketest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/realency/arke/pkg/clock"
+)
+
+// Clock is a fake clock for testing animations, which stands still until it is advanced.
+//
+// Clock implements interface clock.Clock, so it may be selected with clock.With in place of the system clock of
+// anything that animates, such as a transition.  Animations wait on the clock in the background, so tests should call
+// BlockUntil before advancing the clock, to be sure that the animation is waiting for the time to come.
+type Clock struct {
+	mutex   sync.Mutex
+	waiting *sync.Cond
+	now     time.Time
+	waiters []waiter
+}
+
+var _ clock.Clock = (*Clock)(nil)
+
+type waiter struct {
+	due time.Time
+	c   chan time.Time
+}
+
+// NewClock returns a new instance of Clock, set to a given time.
+func NewClock(now time.Time) *Clock {
+	result := &Clock{now: now}
+	result.waiting = sync.NewCond(&result.mutex)
+	return result
+}
+
+// Now returns the current time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward, firing any channels returned by After that fall due.  Returns the new time.
+func (c *Clock) Advance(d time.Duration) time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = c.now.Add(d)
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.due.After(c.now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.c <- c.now
+	}
+	c.waiters = pending
+	return c.now
+}
+
+// After returns a channel on which the time is sent once the clock has been advanced by the given duration.
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	result := make(chan time.Time, 1)
+	if d <= 0 {
+		result <- c.now
+		return result
+	}
+	c.waiters = append(c.waiters, waiter{c.now.Add(d), result})
+	c.waiting.Broadcast()
+	return result
+}
+
+// BlockUntil blocks until at least n channels returned by After are waiting for the clock to be advanced.
+func (c *Clock) BlockUntil(n int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for len(c.waiters) < n {
+		c.waiting.Wait()
+	}
+}
@@ -0,0 +1,63 @@
+package arketest
+
+import (
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/realency/arke/pkg/bits"
+)
+
+// Reports whether the test binary was run with the -update flag, to rewrite golden files from the actual frames.
+// The flag is defined by the test package, rather than here, so that importing arketest adds no flags to a binary
+// and leaves test packages free to define -update for themselves.
+func updating() bool {
+	f := flag.Lookup("update")
+	if f == nil {
+		return false
+	}
+	g, ok := f.Value.(flag.Getter)
+	if !ok {
+		return false
+	}
+	update, ok := g.Get().(bool)
+	return ok && update
+}
+
+// GoldenPath returns the path of the golden file with a given name, relative to the directory of the test package.
+func GoldenPath(name string) string {
+	return filepath.Join("testdata", name+".golden")
+}
+
+// AssertGolden compares a bit matrix with the content of a golden file, as for AssertMatrix.
+// Golden files hold ASCII art, as read by Matrix, and are kept in the testdata directory of the test package.
+// If the test binary is run with the boolean flag -update, which the test package must define, the golden file is
+// written with the matrix instead, and the test passes.
+func AssertGolden(t testing.TB, name string, got *bits.Matrix) {
+	t.Helper()
+	path := GoldenPath(name)
+
+	if updating() {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(Art(got)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Golden file %s does not exist; run the test with -update to create it", path)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := Diff(got, Matrix(string(data))); d != "" {
+		t.Errorf("Frame differs from golden file %s:\n%s", path, d)
+	}
+}
@@ -0,0 +1,125 @@
+package arketest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/realency/arke/pkg/bits"
+)
+
+// Matrix returns a bit matrix drawn as ASCII art, one row per line.
+//
+// Lit pixels are drawn as '#' or '@', and unlit pixels as '.'.  Leading and trailing whitespace is removed from each
+// line, and blank lines before and after the art are ignored, so that art may be indented in a raw string literal.
+// The width of the matrix is the length of the longest line, and shorter lines are padded with unlit pixels.
+// Panics if the art contains any other character.
+func Matrix(art string) *bits.Matrix {
+	lines := strings.Split(strings.TrimSpace(art), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return bits.ZeroMatrix
+	}
+
+	width := 0
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+		if len(lines[i]) > width {
+			width = len(lines[i])
+		}
+	}
+
+	result := bits.NewMatrix(len(lines), width)
+	for row, line := range lines {
+		for col, c := range line {
+			switch c {
+			case '#', '@':
+				result.Set(row, col, true)
+			case '.':
+			default:
+				panic(fmt.Sprintf("Unrecognised character %q in matrix art", c))
+			}
+		}
+	}
+	return result
+}
+
+// Art returns a bit matrix drawn as ASCII art, in the form read by Matrix, with a line break after each row.
+func Art(m *bits.Matrix) string {
+	h, w := m.Size()
+	var sb strings.Builder
+	for row := 0; row < h; row++ {
+		for col := 0; col < w; col++ {
+			if m.Get(row, col) {
+				sb.WriteByte('#')
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// Diff returns a picture of two bit matrices side by side, followed by a picture of their differences, in which
+// '+' is a pixel lit only in got, and '-' is a pixel lit only in want.  Returns an empty string if the matrices are equal.
+func Diff(got, want *bits.Matrix) string {
+	if got.Equal(want) {
+		return ""
+	}
+
+	gh, gw := got.Size()
+	wh, ww := want.Size()
+	height, width := max(gh, wh), max(gw, ww)
+	pixel := func(m *bits.Matrix, row, col int) byte {
+		h, w := m.Size()
+		switch {
+		case row >= h || col >= w:
+			return ' '
+		case m.Get(row, col):
+			return '#'
+		default:
+			return '.'
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%-*s   %-*s   %s\n", max(width, 4), "got", max(width, 4), "want", "diff")
+	for row := 0; row < height; row++ {
+		var g, w, d strings.Builder
+		for col := 0; col < width; col++ {
+			gp, wp := pixel(got, row, col), pixel(want, row, col)
+			g.WriteByte(gp)
+			w.WriteByte(wp)
+			switch {
+			case gp == wp:
+				d.WriteByte(gp)
+			case gp == '#':
+				d.WriteByte('+')
+			case wp == '#':
+				d.WriteByte('-')
+			default:
+				d.WriteByte('?')
+			}
+		}
+		fmt.Fprintf(&sb, "%-*s   %-*s   %s\n", max(width, 4), g.String(), max(width, 4), w.String(), d.String())
+	}
+	if gh != wh || gw != ww {
+		fmt.Fprintf(&sb, "got is %dx%d, want is %dx%d\n", gh, gw, wh, ww)
+	}
+	return sb.String()
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// AssertMatrix reports a test error, with a side-by-side picture of the differences, if two bit matrices differ.
+func AssertMatrix(t testing.TB, got, want *bits.Matrix) {
+	t.Helper()
+	if d := Diff(got, want); d != "" {
+		t.Errorf("Matrices differ:\n%s", d)
+	}
+}
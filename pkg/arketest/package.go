@@ -0,0 +1,11 @@
+// Package arketest provides helpers for testing code that draws to bit matrices, canvases and viewports.
+//
+// Fixtures are written as ASCII art, in which '#' is a lit pixel and '.' is an unlit one, and matrices that differ
+// are reported as side-by-side pictures.  Expected frames may also be kept in golden files, which are rewritten from
+// the actual frames when tests are run with the -update flag.  The flag is not defined here: a test package using
+// golden files defines it, with
+//
+//	var _ = flag.Bool("update", false, "update golden files")
+//
+// A fake clock and a fake viewport, which captures every frame it is shown, complete the set.
+package arketest
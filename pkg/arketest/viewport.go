@@ -0,0 +1,178 @@
+package arketest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/realency/arke/pkg/bits"
+	"github.com/realency/arke/pkg/display"
+	"github.com/realency/arke/pkg/viewport"
+)
+
+// ViewPort is a fake implementation of interface viewport.ViewPort, which captures every frame it is shown.
+//
+// A frame is captured when the ViewPort is attached, each time the canvas is updated, and each time the ViewPort is
+// located.  Updates are captured in the background, as they are by real drivers, so tests should call Flush before
+// inspecting the frames.  Like the drivers, the ViewPort ignores an attempt to attach it to the canvas it is already
+// attached to.
+type ViewPort struct {
+	mutex         sync.Mutex
+	canvas        *display.Canvas
+	feed          *viewport.Feed
+	row, col      int
+	height, width int
+	frames        []*bits.Matrix
+}
+
+// NewViewPort returns a new instance of ViewPort, of a given size.
+func NewViewPort(height, width int) *ViewPort {
+	return &ViewPort{
+		row:    -1,
+		col:    -1,
+		height: height,
+		width:  width,
+	}
+}
+
+// Captures a snapshot passed on by a feed, unless the feed belongs to a previous attachment.
+func (vp *ViewPort) draw(f *viewport.Feed, m *bits.Matrix) {
+	vp.mutex.Lock()
+	defer vp.mutex.Unlock()
+	if f == vp.feed {
+		vp.capture(m)
+	}
+}
+
+// Captures the framed region of a canvas snapshot.
+func (vp *ViewPort) capture(m *bits.Matrix) {
+	frame := bits.NewMatrix(vp.height, vp.width)
+	bits.Copy(m, vp.row, vp.col, frame, 0, 0, vp.height, vp.width)
+	vp.frames = append(vp.frames, frame)
+}
+
+func (vp *ViewPort) locate(row, col int) {
+	h, w := vp.canvas.Size()
+	if row+vp.height > h {
+		row = h - vp.height
+	}
+	if row < 0 {
+		row = 0
+	}
+	if col+vp.width > w {
+		col = w - vp.width
+	}
+	if col < 0 {
+		col = 0
+	}
+	vp.row, vp.col = row, col
+}
+
+// Attach attaches the ViewPort to a canvas at a specific location, and captures the framed region.
+func (vp *ViewPort) Attach(canvas *display.Canvas, row, col int) {
+	if canvas == nil {
+		vp.Detach()
+		return
+	}
+
+	vp.mutex.Lock()
+	defer vp.mutex.Unlock()
+
+	if vp.canvas == canvas {
+		return
+	}
+	if vp.canvas != nil {
+		vp.feed.Stop()
+	}
+	var m *bits.Matrix
+	vp.canvas = canvas
+	vp.feed, m = viewport.NewFeed(canvas, 1024, vp.draw)
+	vp.locate(row, col)
+	vp.capture(m)
+}
+
+// Detach detaches the ViewPort from the canvas it is currently attached to.
+func (vp *ViewPort) Detach() {
+	vp.mutex.Lock()
+	defer vp.mutex.Unlock()
+
+	if vp.canvas == nil {
+		return
+	}
+	vp.feed.Stop()
+	vp.canvas = nil
+	vp.feed = nil
+	vp.row, vp.col = -1, -1
+}
+
+// Locate repositions the ViewPort at a new position on the underlying canvas, and captures the framed region.
+func (vp *ViewPort) Locate(row, col int) {
+	vp.mutex.Lock()
+	defer vp.mutex.Unlock()
+
+	if vp.canvas == nil {
+		return
+	}
+	vp.locate(row, col)
+	vp.capture(vp.canvas.Matrix())
+}
+
+// Offset returns the current location of the ViewPort - its offset into the canvas.
+func (vp *ViewPort) Offset() (row, col int) {
+	vp.mutex.Lock()
+	defer vp.mutex.Unlock()
+	return vp.row, vp.col
+}
+
+// Size returns the size of the ViewPort in pixels.
+func (vp *ViewPort) Size() (height, width int) {
+	return vp.height, vp.width
+}
+
+// Canvas returns the attached canvas, or nil if the ViewPort is not attached to any canvas.
+func (vp *ViewPort) Canvas() *display.Canvas {
+	vp.mutex.Lock()
+	defer vp.mutex.Unlock()
+	return vp.canvas
+}
+
+// Frames returns every frame captured so far, oldest first.
+func (vp *ViewPort) Frames() []*bits.Matrix {
+	vp.mutex.Lock()
+	defer vp.mutex.Unlock()
+	return append([]*bits.Matrix(nil), vp.frames...)
+}
+
+// Last returns the most recently captured frame, or nil if no frame has been captured.
+func (vp *ViewPort) Last() *bits.Matrix {
+	vp.mutex.Lock()
+	defer vp.mutex.Unlock()
+	if len(vp.frames) == 0 {
+		return nil
+	}
+	return vp.frames[len(vp.frames)-1]
+}
+
+// Reset discards the frames captured so far.
+func (vp *ViewPort) Reset() {
+	vp.mutex.Lock()
+	defer vp.mutex.Unlock()
+	vp.frames = nil
+}
+
+// Flush blocks until every update to the canvas made before the call has been captured, or until the context is done,
+// in which case it returns the error of the context.
+func (vp *ViewPort) Flush(ctx context.Context) error {
+	vp.mutex.Lock()
+	feed := vp.feed
+	vp.mutex.Unlock()
+	if feed == nil {
+		return nil
+	}
+	return feed.Flush(ctx)
+}
+
+// Close detaches the ViewPort and stops capturing updates.  Captured frames remain available.
+func (vp *ViewPort) Close() error {
+	vp.Detach()
+	return nil
+}
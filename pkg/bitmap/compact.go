@@ -0,0 +1,312 @@
+package bitmap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/realency/arke/pkg/bits"
+)
+
+// The compact format is a stream of bit matrices of the same size, suited to embedded assets, animations and
+// network protocols.  The stream starts with a header of four magic bytes, which include the format version,
+// followed by the height and width of the matrices.  Each matrix follows, as an encoding byte, the length of
+// the encoded data, and the encoded data.  Numbers are unsigned varints.
+//
+// Matrices are encoded from their rows of pixels, each padded to a whole number of bytes, with the leftmost pixel
+// in the most significant bit of the first byte.  The rows are stored raw, compressed using PackBits, or as the
+// PackBits compression of their exclusive or with the rows of the previous matrix, whichever is smallest.
+// A matrix that repeats the previous one is stored with no data.
+var compactMagic = []byte("AKB1")
+
+// Constant values for the encoding of a matrix within the compact format.
+const (
+	encodingRaw      byte = 0
+	encodingPackBits byte = 1
+	encodingDelta    byte = 2
+	encodingRepeat   byte = 3
+)
+
+// Limits on the size of matrices in the compact format, to guard against corrupt headers: 1<<24 pixels take 2MB
+const (
+	maxCompactSize   = 1 << 16
+	maxCompactPixels = 1 << 24
+)
+
+// Returns whether matrices of a given size are within the limits of the compact format.
+func compactSize(height, width uint64) bool {
+	return height <= maxCompactSize && width <= maxCompactSize && height*width <= maxCompactPixels
+}
+
+// ErrCompact is returned by a Decoder when its input is not valid compact data.
+var ErrCompact = errors.New("bitmap: invalid compact data")
+
+// Returns the rows of a matrix, each padded to a whole number of bytes.
+func rowBytes(m *bits.Matrix) []byte {
+	h, w := m.Size()
+	stride := (w + 7) / 8
+	result := make([]byte, h*stride)
+	for row := 0; row < h; row++ {
+		c := bits.NewCursor(m, row, 0)
+		for i := 0; i < stride; i++ {
+			b, n := c.ReadRightByte()
+			result[row*stride+i] = b << (8 - n)
+		}
+	}
+	return result
+}
+
+// Returns a matrix from its rows, each padded to a whole number of bytes.
+func fromRowBytes(data []byte, height, width int) *bits.Matrix {
+	result := bits.NewMatrix(height, width)
+	stride := (width + 7) / 8
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			if data[row*stride+col/8]&(0x80>>(col%8)) != 0 {
+				result.Set(row, col, true)
+			}
+		}
+	}
+	return result
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	return append(buf, b[:n]...)
+}
+
+// An Encoder writes bit matrices of the same size to an output stream in the compact format.
+type Encoder struct {
+	w             io.Writer
+	height, width int
+	header        bool
+	delta         bool
+	previous      []byte
+}
+
+// NewEncoder returns a new instance of Encoder, which writes to w.  The size of the matrices is taken from the first
+// matrix encoded.  Matrices after the first may be encoded against the matrix before them.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w, delta: true}
+}
+
+// SetDelta sets whether matrices may be encoded as their differences from the matrix before them.  Without delta
+// encoding, each matrix can be decoded independently, at some cost in size.  Delta encoding is enabled by default.
+func (e *Encoder) SetDelta(delta bool) {
+	e.delta = delta
+}
+
+// WriteHeader writes the header of the stream, for matrices of a given size, if it has not already been written.
+// Encode writes the header before the first matrix, taking the size from it, so WriteHeader is needed only to
+// record the size of a stream with no matrices.  Returns an error if the size is beyond the limits of the format.
+func (e *Encoder) WriteHeader(height, width int) error {
+	if e.header {
+		return nil
+	}
+	if !compactSize(uint64(height), uint64(width)) {
+		return fmt.Errorf("bitmap: matrix size %dx%d is too large for compact data", height, width)
+	}
+	buf := append([]byte(nil), compactMagic...)
+	buf = appendUvarint(buf, uint64(height))
+	buf = appendUvarint(buf, uint64(width))
+	if _, err := e.w.Write(buf); err != nil {
+		return fmt.Errorf("bitmap: writing compact data: %w", err)
+	}
+	e.height, e.width = height, width
+	e.header = true
+	return nil
+}
+
+// Encode writes a bit matrix.  Returns an error if the matrix is not the same size as the first matrix encoded.
+func (e *Encoder) Encode(m *bits.Matrix) error {
+	h, w := m.Size()
+	if !e.header {
+		if err := e.WriteHeader(h, w); err != nil {
+			return err
+		}
+	} else if h != e.height || w != e.width {
+		return fmt.Errorf("bitmap: matrix size %dx%d does not match stream size %dx%d", h, w, e.height, e.width)
+	}
+
+	raw := rowBytes(m)
+	encoding, data := encodingRaw, raw
+	if packed := PackBits(nil, raw); len(packed) < len(data) {
+		encoding, data = encodingPackBits, packed
+	}
+	if e.delta && e.previous != nil {
+		if bytes.Equal(raw, e.previous) {
+			encoding, data = encodingRepeat, nil
+		} else {
+			diff := make([]byte, len(raw))
+			for i := range raw {
+				diff[i] = raw[i] ^ e.previous[i]
+			}
+			if packed := PackBits(nil, diff); len(packed) < len(data) {
+				encoding, data = encodingDelta, packed
+			}
+		}
+	}
+
+	buf := []byte{encoding}
+	buf = appendUvarint(buf, uint64(len(data)))
+	buf = append(buf, data...)
+	if _, err := e.w.Write(buf); err != nil {
+		return fmt.Errorf("bitmap: writing compact data: %w", err)
+	}
+	e.previous = raw
+	return nil
+}
+
+// A Decoder reads bit matrices from an input stream in the compact format.
+type Decoder struct {
+	r             *bufio.Reader
+	header        bool
+	height, width int
+	previous      []byte
+}
+
+// NewDecoder returns a new instance of Decoder, which reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &Decoder{r: br}
+}
+
+// Size returns the size of the matrices in the stream, reading the header of the stream if necessary.
+func (d *Decoder) Size() (height, width int, err error) {
+	if err := d.readHeader(); err != nil {
+		return 0, 0, err
+	}
+	return d.height, d.width, nil
+}
+
+func (d *Decoder) readHeader() error {
+	if d.header {
+		return nil
+	}
+
+	magic := make([]byte, len(compactMagic))
+	if _, err := io.ReadFull(d.r, magic); err != nil {
+		return d.fail(err)
+	}
+	if string(magic) != string(compactMagic) {
+		return ErrCompact
+	}
+	h, err := d.uvarint()
+	if err != nil {
+		return err
+	}
+	w, err := d.uvarint()
+	if err != nil {
+		return err
+	}
+	if !compactSize(h, w) {
+		return ErrCompact
+	}
+
+	d.height, d.width = int(h), int(w)
+	d.header = true
+	return nil
+}
+
+// Returns the error to report for a failure to read, treating a premature end of the stream as invalid data.
+func (d *Decoder) fail(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrCompact
+	}
+	return fmt.Errorf("bitmap: reading compact data: %w", err)
+}
+
+func (d *Decoder) uvarint() (uint64, error) {
+	v, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return 0, d.fail(err)
+	}
+	return v, nil
+}
+
+// Decode reads the next bit matrix.  Returns io.EOF when there are no more matrices.
+func (d *Decoder) Decode() (*bits.Matrix, error) {
+	if err := d.readHeader(); err != nil {
+		return nil, err
+	}
+
+	encoding, err := d.r.ReadByte()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, d.fail(err)
+	}
+	length, err := d.uvarint()
+	if err != nil {
+		return nil, err
+	}
+
+	size := d.height * ((d.width + 7) / 8)
+	if length > uint64(size) {
+		return nil, ErrCompact
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(d.r, data); err != nil {
+		return nil, d.fail(err)
+	}
+
+	var raw []byte
+	switch encoding {
+	case encodingRaw:
+		if len(data) != size {
+			return nil, ErrCompact
+		}
+		raw = data
+	case encodingPackBits:
+		if raw, err = UnpackBits(nil, data, size); err != nil {
+			return nil, ErrCompact
+		}
+	case encodingDelta:
+		if d.previous == nil {
+			return nil, ErrCompact
+		}
+		if raw, err = UnpackBits(nil, data, size); err != nil {
+			return nil, ErrCompact
+		}
+		for i := range raw {
+			raw[i] ^= d.previous[i]
+		}
+	case encodingRepeat:
+		if d.previous == nil || len(data) != 0 {
+			return nil, ErrCompact
+		}
+		raw = d.previous
+	default:
+		return nil, ErrCompact
+	}
+
+	d.previous = raw
+	return fromRowBytes(raw, d.height, d.width), nil
+}
+
+// Pack returns a single bit matrix in the compact format, for example to embed as an asset.  Returns an error if
+// the matrix is too large for the format.
+func Pack(m *bits.Matrix) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unpack returns the first bit matrix from data in the compact format.
+func Unpack(data []byte) (*bits.Matrix, error) {
+	m, err := NewDecoder(bytes.NewReader(data)).Decode()
+	if err == io.EOF {
+		return nil, ErrCompact
+	}
+	return m, err
+}
@@ -0,0 +1,156 @@
+package bitmap
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+
+	// Register decoders for the image formats most commonly used for display content
+	_ "image/gif"
+	_ "image/png"
+
+	"github.com/realency/arke/pkg/bits"
+)
+
+// Decode reads an image and returns it as a bit matrix.
+// PBM files are decoded directly, with black pixels set; other formats are decoded using FromImage.
+func Decode(r io.Reader) (*bits.Matrix, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err != nil {
+		return nil, fmt.Errorf("bitmap: reading image: %w", err)
+	}
+
+	if magic[0] == 'P' && (magic[1] == '1' || magic[1] == '4') {
+		return DecodePBM(br)
+	}
+
+	img, _, err := image.Decode(br)
+	if err != nil {
+		return nil, fmt.Errorf("bitmap: decoding image: %w", err)
+	}
+	return FromImage(img), nil
+}
+
+// FromImage converts an image to a bit matrix, in which pixels brighter than half intensity are set.
+// Fully transparent pixels are never set.
+func FromImage(img image.Image) *bits.Matrix {
+	b := img.Bounds()
+	result := bits.NewMatrix(b.Dy(), b.Dx())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := img.At(x, y)
+			if _, _, _, a := c.RGBA(); a == 0 {
+				continue
+			}
+			if color.GrayModel.Convert(c).(color.Gray).Y >= 0x80 {
+				result.Set(y-b.Min.Y, x-b.Min.X, true)
+			}
+		}
+	}
+	return result
+}
+
+// DecodePBM reads a Netpbm bitmap, in either the plain (P1) or raw (P4) format, and returns it as a bit matrix.
+// Following the PBM convention, 1 represents black, and black pixels are set in the result.
+func DecodePBM(r io.Reader) (*bits.Matrix, error) {
+	br := bufio.NewReader(r)
+
+	magic, err := pbmToken(br)
+	if err != nil {
+		return nil, err
+	}
+	if magic != "P1" && magic != "P4" {
+		return nil, errors.New("bitmap: not a PBM file")
+	}
+
+	var width, height int
+	for _, dim := range []*int{&width, &height} {
+		tok, err := pbmToken(br)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := fmt.Sscanf(tok, "%d", dim); err != nil || *dim < 0 {
+			return nil, fmt.Errorf("bitmap: invalid PBM dimension %q", tok)
+		}
+	}
+
+	result := bits.NewMatrix(height, width)
+
+	if magic == "P1" {
+		for row := 0; row < height; row++ {
+			for col := 0; col < width; col++ {
+				b, err := pbmPlainBit(br)
+				if err != nil {
+					return nil, err
+				}
+				if b {
+					result.Set(row, col, true)
+				}
+			}
+		}
+		return result, nil
+	}
+
+	line := make([]byte, (width+7)/8)
+	for row := 0; row < height; row++ {
+		if _, err := io.ReadFull(br, line); err != nil {
+			return nil, fmt.Errorf("bitmap: reading PBM data: %w", err)
+		}
+		for col := 0; col < width; col++ {
+			if line[col/8]&(0x80>>(col%8)) != 0 {
+				result.Set(row, col, true)
+			}
+		}
+	}
+	return result, nil
+}
+
+// Reads a whitespace-delimited header token from a PBM file, skipping comments.
+// Consumes the single whitespace character that follows the token.
+func pbmToken(br *bufio.Reader) (string, error) {
+	var tok []byte
+	for {
+		c, err := br.ReadByte()
+		if err != nil {
+			if len(tok) > 0 && err == io.EOF {
+				return string(tok), nil
+			}
+			return "", fmt.Errorf("bitmap: reading PBM header: %w", err)
+		}
+		switch {
+		case c == '#':
+			if _, err := br.ReadString('\n'); err != nil {
+				return "", fmt.Errorf("bitmap: reading PBM header: %w", err)
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if len(tok) > 0 {
+				return string(tok), nil
+			}
+		default:
+			tok = append(tok, c)
+		}
+	}
+}
+
+func pbmPlainBit(br *bufio.Reader) (bool, error) {
+	for {
+		c, err := br.ReadByte()
+		if err != nil {
+			return false, fmt.Errorf("bitmap: reading PBM data: %w", err)
+		}
+		switch c {
+		case '0':
+			return false, nil
+		case '1':
+			return true, nil
+		case '#':
+			if _, err := br.ReadString('\n'); err != nil {
+				return false, fmt.Errorf("bitmap: reading PBM data: %w", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,10 @@
+// Package bitmap converts between bit matrices and image files.
+//
+// Images are read from Netpbm bitmap (PBM) files, or from any format registered with the standard image
+// package, such as PNG and GIF.  Colour and greyscale images are reduced to one bit per pixel by comparing
+// the brightness of each pixel with a threshold.
+//
+// Bit matrices may also be stored in a compact format of their own, using PackBits run-length encoding, and for
+// sequences of frames, encoding each frame as its difference from the one before.  The format is read and written
+// as a stream, by a Decoder and an Encoder, or a single matrix at a time, by Unpack and Pack.
+package bitmap
@@ -0,0 +1,71 @@
+package bitmap
+
+import (
+	"errors"
+)
+
+// ErrPackBits is returned by UnpackBits when its input is not valid PackBits data.
+var ErrPackBits = errors.New("bitmap: invalid PackBits data")
+
+// PackBits compresses bytes using the PackBits run-length encoding, and appends the result to dst.
+//
+// The input is encoded as a series of packets, each starting with a header byte n: if n is in the range 0..127, it is
+// followed by n+1 literal bytes, and if n is in the range -127..-1, it is followed by a single byte to be repeated 1-n times.
+func PackBits(dst, src []byte) []byte {
+	for i := 0; i < len(src); {
+		j := i + 1
+		for j < len(src) && src[j] == src[i] && j-i < 128 {
+			j++
+		}
+		if j-i >= 2 {
+			dst = append(dst, byte(1-(j-i)), src[i])
+			i = j
+			continue
+		}
+
+		// Literal bytes continue until the start of a run long enough to be worth encoding separately
+		k := i
+		for k < len(src) && k-i < 128 {
+			if k+2 < len(src) && src[k] == src[k+1] && src[k] == src[k+2] {
+				break
+			}
+			k++
+		}
+		dst = append(dst, byte(k-i-1))
+		dst = append(dst, src[i:k]...)
+		i = k
+	}
+	return dst
+}
+
+// UnpackBits decompresses PackBits data that decodes to exactly n bytes, and appends the result to dst.
+func UnpackBits(dst, src []byte, n int) ([]byte, error) {
+	end := len(dst) + n
+	for i := 0; i < len(src); {
+		header := int(int8(src[i]))
+		i++
+		switch {
+		case header >= 0:
+			count := header + 1
+			if i+count > len(src) || len(dst)+count > end {
+				return nil, ErrPackBits
+			}
+			dst = append(dst, src[i:i+count]...)
+			i += count
+		case header > -128:
+			count := 1 - header
+			if i >= len(src) || len(dst)+count > end {
+				return nil, ErrPackBits
+			}
+			for k := 0; k < count; k++ {
+				dst = append(dst, src[i])
+			}
+			i++
+		}
+		// A header of -128 is a no-op
+	}
+	if len(dst) != end {
+		return nil, ErrPackBits
+	}
+	return dst, nil
+}
@@ -0,0 +1,156 @@
+package bits
+
+import (
+	mathbits "math/bits"
+)
+
+// FNV-1a parameters, used by Hash
+const (
+	fnvOffset uint64 = 14695981039346656037
+	fnvPrime  uint64 = 1099511628211
+)
+
+// Returns a mask selecting the bits of word k of a row that fall within the columns from..to-1.
+// Bits beyond the width of the matrix, in the last word of each row, are never selected by a caller passing to <= width.
+func spanMask(k, from, to int) uint32 {
+	lo, hi := from-32*k, to-32*k
+	if lo < 0 {
+		lo = 0
+	}
+	if hi > 32 {
+		hi = 32
+	}
+	if lo >= hi {
+		return 0
+	}
+	return (0xFFFFFFFF >> uint(lo)) &^ (0xFFFFFFFF >> uint(hi))
+}
+
+// Returns word k of a row, with any bits beyond the width of the matrix cleared.
+func (m *Matrix) word(row, k int) uint32 {
+	w := m.bits[m.intsPerRow*row+k]
+	if k == m.intsPerRow-1 {
+		w &= spanMask(k, 0, m.width)
+	}
+	return w
+}
+
+// Equal reports whether two matrices have the same size and the same bits.
+func (m *Matrix) Equal(other *Matrix) bool {
+	if m.height != other.height || m.width != other.width {
+		return false
+	}
+	for row := 0; row < m.height; row++ {
+		for k := 0; k < m.intsPerRow; k++ {
+			if m.word(row, k) != other.word(row, k) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Hash returns a hash of the size and bits of the matrix, which is stable between processes, and so may be used as
+// a key for caching rendered frames.  Equal matrices have equal hashes.
+func (m *Matrix) Hash() uint64 {
+	h := fnvOffset
+	mix := func(v uint32) {
+		for shift := 24; shift >= 0; shift -= 8 {
+			h ^= uint64(byte(v >> uint(shift)))
+			h *= fnvPrime
+		}
+	}
+
+	mix(uint32(m.height))
+	mix(uint32(m.width))
+	for row := 0; row < m.height; row++ {
+		for k := 0; k < m.intsPerRow; k++ {
+			mix(m.word(row, k))
+		}
+	}
+	return h
+}
+
+// PopCount returns the number of bits set in the matrix.
+func (m *Matrix) PopCount() int {
+	result := 0
+	for row := 0; row < m.height; row++ {
+		for k := 0; k < m.intsPerRow; k++ {
+			result += mathbits.OnesCount32(m.word(row, k))
+		}
+	}
+	return result
+}
+
+// PopCountRegion returns the number of bits set in a rectangular region of the matrix.
+// The region is clipped to the extent of the matrix.
+func (m *Matrix) PopCountRegion(row, col, height, width int) int {
+	top, bottom := clip(row, row+height, m.height)
+	left, right := clip(col, col+width, m.width)
+
+	result := 0
+	for r := top; r < bottom; r++ {
+		for k := left / 32; k*32 < right; k++ {
+			result += mathbits.OnesCount32(m.bits[m.intsPerRow*r+k] & spanMask(k, left, right))
+		}
+	}
+	return result
+}
+
+// Clips the range from..to-1 to the range 0..size-1.
+func clip(from, to, size int) (int, int) {
+	if from < 0 {
+		from = 0
+	}
+	if to > size {
+		to = size
+	}
+	if to < from {
+		to = from
+	}
+	return from, to
+}
+
+// BoundingBox returns the smallest rectangle enclosing every bit set in the matrix.
+// Returns a height and width of zero if no bits are set.
+func (m *Matrix) BoundingBox() (row, col, height, width int) {
+	top, bottom := -1, -1
+	columns := make([]uint32, m.intsPerRow)
+	for r := 0; r < m.height; r++ {
+		lit := uint32(0)
+		for k := range columns {
+			w := m.word(r, k)
+			columns[k] |= w
+			lit |= w
+		}
+		if lit != 0 {
+			if top < 0 {
+				top = r
+			}
+			bottom = r
+		}
+	}
+	if top < 0 {
+		return 0, 0, 0, 0
+	}
+
+	left, right := -1, -1
+	for k, w := range columns {
+		if w == 0 {
+			continue
+		}
+		if left < 0 {
+			left = 32*k + mathbits.LeadingZeros32(w)
+		}
+		right = 32*k + 31 - mathbits.TrailingZeros32(w)
+	}
+	return top, left, bottom - top + 1, right - left + 1
+}
+
+// Trim returns a copy of the region of the matrix within its bounding box.  Returns ZeroMatrix if no bits are set.
+func (m *Matrix) Trim() *Matrix {
+	row, col, height, width := m.BoundingBox()
+	result := NewMatrix(height, width)
+	Copy(m, row, col, result, 0, 0, height, width)
+	return result
+}
@@ -100,6 +100,7 @@ func streamCopy(source, dest *stream) int {
 			}
 			count += writeCount
 			readCount -= writeCount
+			buff <<= writeCount
 		}
 	}
 }
@@ -0,0 +1,154 @@
+package brightness
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/realency/arke/pkg/clock"
+)
+
+// LightSensor is implemented by ambient light sensors.
+type LightSensor interface {
+	// Lux returns the current illuminance, in lux.
+	Lux() (float64, error)
+}
+
+// DefaultThresholds are the illuminances, in lux, at which an Auto controller steps up to each level from 1 to 15.
+// Below the first threshold, the display is set to level 0.
+var DefaultThresholds = []float64{1, 3, 6, 10, 20, 40, 70, 100, 200, 400, 700, 1000, 3000, 10000, 30000}
+
+// DefaultHysteresis is the default fraction by which the illuminance must pass a threshold before an Auto controller changes level.
+const DefaultHysteresis = 0.2
+
+// DefaultFadeTime is the default time over which an Auto controller fades the display to a new level.
+const DefaultFadeTime = time.Second
+
+// Auto sets the brightness of a display automatically, from the readings of an ambient light sensor.
+//
+// Illuminance is mapped to a level by a list of thresholds, with hysteresis so that readings close to a threshold do not
+// cause the level to flicker: the level steps up only once the illuminance exceeds a threshold by the hysteresis fraction,
+// and steps down only once it falls below a threshold by the same fraction.
+type Auto struct {
+	mutex      sync.Mutex
+	clock      clock.Clock
+	sensor     LightSensor
+	dimmer     *Dimmer
+	thresholds []float64
+	hysteresis float64
+	fadeTime   time.Duration
+	stop       chan struct{}
+}
+
+// NewAuto returns a new instance of Auto, which controls a display through a Dimmer using the readings of a sensor.
+// Samples are timed by the system clock, unless another is selected by an option.
+func NewAuto(sensor LightSensor, dimmer *Dimmer, opts ...clock.Option) *Auto {
+	return &Auto{
+		clock:      clock.Of(opts),
+		sensor:     sensor,
+		dimmer:     dimmer,
+		thresholds: DefaultThresholds,
+		hysteresis: DefaultHysteresis,
+		fadeTime:   DefaultFadeTime,
+	}
+}
+
+// SetThresholds sets the illuminances, in lux, at which the controller steps up to each level from 1 upwards.
+// The number of thresholds is the highest level used, which should not be more than MaxLevel.
+func (a *Auto) SetThresholds(thresholds []float64) {
+	t := append([]float64(nil), thresholds...)
+	sort.Float64s(t)
+	if len(t) > int(MaxLevel) {
+		t = t[:MaxLevel]
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.thresholds = t
+}
+
+// SetHysteresis sets the fraction by which the illuminance must pass a threshold before the level changes.
+func (a *Auto) SetHysteresis(hysteresis float64) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.hysteresis = hysteresis
+}
+
+// SetFadeTime sets the time over which the display fades to a new level.  Zero changes the level immediately.
+func (a *Auto) SetFadeTime(fadeTime time.Duration) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.fadeTime = fadeTime
+}
+
+// Returns the level for an illuminance: the number of thresholds it reaches.
+func (a *Auto) levelFor(lux float64) byte {
+	return byte(sort.Search(len(a.thresholds), func(i int) bool { return a.thresholds[i] > lux }))
+}
+
+// Sample takes a reading from the sensor, and changes the brightness of the display if required.
+// Returns the error from the sensor, if any, leaving the brightness unchanged.
+func (a *Auto) Sample() error {
+	lux, err := a.sensor.Lux()
+	if err != nil {
+		return err
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	current := a.dimmer.Level()
+	level := current
+	if up := a.levelFor(lux / (1 + a.hysteresis)); up > current {
+		level = up
+	} else if down := a.levelFor(lux * (1 + a.hysteresis)); down < current {
+		level = down
+	}
+	if level == current {
+		return nil
+	}
+
+	if a.fadeTime > 0 {
+		a.dimmer.FadeTo(level, a.fadeTime)
+	} else {
+		a.dimmer.Set(level)
+	}
+	return nil
+}
+
+// Start samples the sensor immediately, and then at the given interval, until Stop is called.
+// Sensor errors are logged, and the brightness left unchanged.
+func (a *Auto) Start(interval time.Duration) {
+	a.mutex.Lock()
+	if a.stop != nil {
+		a.mutex.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	a.stop = stop
+	a.mutex.Unlock()
+
+	go func() {
+		for {
+			if err := a.Sample(); err != nil {
+				log.Println("WARNING Light sensor failed:", err)
+			}
+			select {
+			case <-stop:
+				return
+			case <-a.clock.After(interval):
+			}
+		}
+	}()
+}
+
+// Stop stops sampling the sensor, leaving the display at its current brightness.
+func (a *Auto) Stop() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.stop != nil {
+		close(a.stop)
+		a.stop = nil
+	}
+}
@@ -0,0 +1,46 @@
+package brightness
+
+import (
+	"fmt"
+
+	"periph.io/x/conn/v3/i2c"
+)
+
+// I2C addresses of the BH1750, selected by the level of its ADDR pin.
+const (
+	BH1750AddrLow  uint16 = 0x23
+	BH1750AddrHigh uint16 = 0x5C
+)
+
+// BH1750 instructions
+const (
+	bh1750PowerOn        byte = 0x01
+	bh1750ContinuousHigh byte = 0x10
+)
+
+// BH1750 is a LightSensor for the BH1750 ambient light sensor, attached over I2C.
+type BH1750 struct {
+	dev *i2c.Dev
+}
+
+// NewBH1750 returns a new instance of BH1750 at an address on an I2C bus, and starts continuous high-resolution measurement.
+// The first reading is available once a measurement has completed, after about 180ms.
+func NewBH1750(bus i2c.Bus, addr uint16) (*BH1750, error) {
+	dev := &i2c.Dev{Bus: bus, Addr: addr}
+	if err := dev.Tx([]byte{bh1750PowerOn}, nil); err != nil {
+		return nil, fmt.Errorf("brightness: powering on BH1750: %w", err)
+	}
+	if err := dev.Tx([]byte{bh1750ContinuousHigh}, nil); err != nil {
+		return nil, fmt.Errorf("brightness: starting BH1750 measurement: %w", err)
+	}
+	return &BH1750{dev: dev}, nil
+}
+
+// Lux returns the illuminance most recently measured by the sensor, in lux.
+func (s *BH1750) Lux() (float64, error) {
+	var data [2]byte
+	if err := s.dev.Tx(nil, data[:]); err != nil {
+		return 0, fmt.Errorf("brightness: reading BH1750: %w", err)
+	}
+	return float64(uint16(data[0])<<8|uint16(data[1])) / 1.2, nil
+}
@@ -0,0 +1,212 @@
+package brightness
+
+import (
+	"sync"
+	"time"
+
+	"github.com/realency/arke/pkg/clock"
+	"github.com/realency/arke/pkg/viewport"
+)
+
+// MaxLevel is the highest brightness level.  Levels range from 0 to MaxLevel, inclusively.
+const MaxLevel byte = 15
+
+// MinBreathStep is the shortest time for which a breathing effect holds each level, so that the effect cannot
+// occupy the processor changing levels in a tight loop.
+const MinBreathStep = time.Millisecond
+
+// Dimmer controls the brightness of a display, changing it immediately or gradually.
+//
+// Each change cancels any fade or breathing effect already in progress.  Levels above MaxLevel are treated as MaxLevel.
+type Dimmer struct {
+	mutex  sync.Mutex
+	target viewport.Brightness
+	clock  clock.Clock
+	level  byte
+	cancel chan struct{}
+}
+
+// NewDimmer returns a new instance of Dimmer, which immediately sets the brightness of the display to the given level.
+// Fades and breathing effects are timed by the system clock, unless another is selected by an option.
+func NewDimmer(target viewport.Brightness, level byte, opts ...clock.Option) *Dimmer {
+	level = clamp(level)
+	target.SetBrightness(level)
+	return &Dimmer{
+		target: target,
+		clock:  clock.Of(opts),
+		level:  level,
+	}
+}
+
+func clamp(level byte) byte {
+	if level > MaxLevel {
+		return MaxLevel
+	}
+	return level
+}
+
+// Level returns the current brightness of the display.
+func (d *Dimmer) Level() byte {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.level
+}
+
+// Set sets the brightness of the display immediately.
+func (d *Dimmer) Set(level byte) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.stop()
+	d.set(clamp(level))
+}
+
+// Stop stops any fade or breathing effect in progress, leaving the display at its current brightness.
+func (d *Dimmer) Stop() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.stop()
+}
+
+func (d *Dimmer) stop() {
+	if d.cancel != nil {
+		close(d.cancel)
+		d.cancel = nil
+	}
+}
+
+func (d *Dimmer) set(level byte) {
+	if level != d.level {
+		d.level = level
+		d.target.SetBrightness(level)
+	}
+}
+
+// FadeTo changes the brightness of the display gradually from its current level to another, over a given duration.
+// Returns a channel that is closed when the fade completes or is cancelled.
+func (d *Dimmer) FadeTo(level byte, duration time.Duration) <-chan struct{} {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.stop()
+	return d.start(fade(d.level, clamp(level), duration))
+}
+
+// FadeIn changes the brightness of the display gradually to MaxLevel.
+func (d *Dimmer) FadeIn(duration time.Duration) <-chan struct{} {
+	return d.FadeTo(MaxLevel, duration)
+}
+
+// FadeOut changes the brightness of the display gradually to zero.
+func (d *Dimmer) FadeOut(duration time.Duration) <-chan struct{} {
+	return d.FadeTo(0, duration)
+}
+
+// CrossFade sets the brightness of the display to one level, and then changes it gradually to another, over a given duration.
+func (d *Dimmer) CrossFade(from, to byte, duration time.Duration) <-chan struct{} {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.stop()
+	d.set(clamp(from))
+	return d.start(fade(clamp(from), clamp(to), duration))
+}
+
+// Breathe changes the brightness of the display repeatedly, from its current level down to low, up to high and back,
+// with each full cycle from high to high taking the given period.  Continues until cancelled by another change.
+// Returns a channel that is closed when the effect is cancelled.
+//
+// Each step of the cycle takes at least MinBreathStep, however short the period.  Panics if the period is not positive.
+func (d *Dimmer) Breathe(low, high byte, period time.Duration) <-chan struct{} {
+	if period <= 0 {
+		panic("Breathing period must be positive")
+	}
+	low, high = clamp(low), clamp(high)
+	if low > high {
+		low, high = high, low
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.stop()
+
+	steps := fade(d.level, low, period/2)
+	cycle := append(fade(low, high, period/2), fade(high, low, period/2)...)
+	for i := range cycle {
+		if cycle[i].delay < MinBreathStep {
+			cycle[i].delay = MinBreathStep
+		}
+	}
+	return d.startRepeating(steps, cycle)
+}
+
+// A step sets a level after a delay.
+type step struct {
+	delay time.Duration
+	level byte
+}
+
+// Returns the steps that change the brightness one level at a time, evenly spaced over a duration.
+func fade(from, to byte, duration time.Duration) []step {
+	n := int(to) - int(from)
+	if n < 0 {
+		n = -n
+	}
+	if n == 0 {
+		return nil
+	}
+
+	result := make([]step, n)
+	delay := duration / time.Duration(n)
+	for i := range result {
+		level := int(from) + i + 1
+		if to < from {
+			level = int(from) - i - 1
+		}
+		result[i] = step{delay, byte(level)}
+	}
+	return result
+}
+
+func (d *Dimmer) start(steps []step) <-chan struct{} {
+	return d.startRepeating(steps, nil)
+}
+
+// Runs the given steps, and then the cycle of steps repeatedly, if given.  Called with the mutex held.
+func (d *Dimmer) startRepeating(steps, cycle []step) <-chan struct{} {
+	cancel := make(chan struct{})
+	done := make(chan struct{})
+	d.cancel = cancel
+
+	go func() {
+		defer close(done)
+		for {
+			for _, s := range steps {
+				select {
+				case <-cancel:
+					return
+				case <-d.clock.After(s.delay):
+				}
+
+				d.mutex.Lock()
+				select {
+				case <-cancel:
+					d.mutex.Unlock()
+					return
+				default:
+				}
+				d.set(s.level)
+				d.mutex.Unlock()
+			}
+			if len(cycle) == 0 {
+				break
+			}
+			steps = cycle
+		}
+
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+		if d.cancel == cancel {
+			d.cancel = nil
+		}
+	}()
+
+	return done
+}
@@ -0,0 +1,3 @@
+// Package brightness provides smooth changes in the brightness of displays: fades, breathing effects, and automatic
+// brightness driven by an ambient light sensor.
+package brightness
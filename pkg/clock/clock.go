@@ -0,0 +1,44 @@
+package clock
+
+import "time"
+
+// Clock tells the time, and waits for time to pass.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// After returns a channel on which the current time is sent once the given duration has passed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// System is the Clock of the operating system.
+var System Clock = system{}
+
+type system struct{}
+
+func (system) Now() time.Time {
+	return time.Now()
+}
+
+func (system) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Option selects the clock timing something animated, when given to the function that creates or starts it.
+type Option func(*Clock)
+
+// With returns an Option selecting a given clock.
+func With(c Clock) Option {
+	return func(target *Clock) {
+		*target = c
+	}
+}
+
+// Of returns the clock selected by the given options, or System if none selects a clock.
+func Of(opts []Option) Clock {
+	result := System
+	for _, opt := range opts {
+		opt(&result)
+	}
+	return result
+}
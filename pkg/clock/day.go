@@ -0,0 +1,21 @@
+package clock
+
+import "time"
+
+// TimeOfDay returns the time of day of t, as an offset from midnight.  The time of day is read from the wall clock,
+// so that daily periods keep to local time on days when the clocks change.
+func TimeOfDay(t time.Time) time.Duration {
+	h, m, s := t.Clock()
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second +
+		time.Duration(t.Nanosecond())
+}
+
+// Between reports whether a time of day falls within the daily period from one time of day to another, including
+// the start of the period but not its end.  A period for which to is earlier than from spans midnight, and a period
+// for which they are equal is empty.
+func Between(tod, from, to time.Duration) bool {
+	if from <= to {
+		return tod >= from && tod < to
+	}
+	return tod >= from || tod < to
+}
@@ -0,0 +1,6 @@
+// Package clock abstracts the passage of time for the animated parts of arke.  Anything that animates takes optional
+// Options selecting its clock, so that tests may replace the system clock with a fake one that only moves when the
+// test advances it.
+//
+// The package also reads times of day, for the daily periods of schedules such as viewport.PowerSchedule.
+package clock
@@ -0,0 +1,239 @@
+package config
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/realency/arke/pkg/display"
+	"github.com/realency/arke/pkg/max7219"
+	"github.com/realency/arke/pkg/terminal"
+	"github.com/realency/arke/pkg/viewport"
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/conn/v3/spi"
+)
+
+// System is the set of buses, viewports and canvases built from a configuration.
+// Viewports are attached to their canvases, and are keyed by the name of the display they drive.
+type System struct {
+	Buses     map[string]max7219.Bus
+	ViewPorts map[string]viewport.ViewPort
+	Canvases  map[string]*display.Canvas
+
+	drivers []viewport.ViewPort    // Viewports driving hardware directly, rather than combining others
+	roots   []viewport.ViewPort    // Viewports not contained in others, which close those they contain
+	given   map[string]max7219.Bus // Buses given to the build, rather than opened by it
+}
+
+// Build builds the system described by the configuration, opening SPI buses as required.
+func (c *Config) Build() (*System, error) {
+	return c.BuildWith(nil)
+}
+
+// BuildWith builds the system described by the configuration, using pre-created buses where given.
+// Buses are keyed by name; any bus not given is opened using its configured device.
+//
+// If building fails part way, the buses and viewports already opened are closed.  As closing a MAX7219 viewport
+// closes its bus, this includes any bus given that a viewport had already been built on.
+func (c *Config) BuildWith(buses map[string]max7219.Bus) (*System, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	s := &System{
+		Buses:     make(map[string]max7219.Bus),
+		ViewPorts: make(map[string]viewport.ViewPort),
+		Canvases:  make(map[string]*display.Canvas),
+		given:     buses,
+	}
+
+	for i, b := range c.Buses {
+		if bus, ok := buses[b.Name]; ok {
+			s.Buses[b.Name] = bus
+			continue
+		}
+		bus, err := busBuilder(b).Build()
+		if err != nil {
+			field := fmt.Sprintf("buses[%d].device", i)
+			if b.Pins != nil {
+				field = fmt.Sprintf("buses[%d].pins", i)
+			}
+			s.release()
+			return nil, &FieldError{Field: field, Message: err.Error()}
+		}
+		s.Buses[b.Name] = bus
+	}
+
+	displays := make(map[string]int)
+	for i, d := range c.Displays {
+		displays[d.Name] = i
+	}
+	panels := make(map[string]bool)
+	for _, d := range c.Displays {
+		for _, p := range d.Panels {
+			panels[p.Display] = true
+		}
+	}
+	for _, d := range c.Displays {
+		vp, err := s.viewPort(c, d.Name, displays)
+		if err != nil {
+			s.release()
+			return nil, err
+		}
+		if !panels[d.Name] {
+			s.roots = append(s.roots, vp)
+		}
+	}
+
+	for _, cv := range c.Canvases {
+		canvas := display.NewCanvas(cv.Height, cv.Width)
+		for _, a := range cv.ViewPorts {
+			s.ViewPorts[a.Display].Attach(canvas, a.Row, a.Col)
+		}
+		s.Canvases[cv.Name] = canvas
+	}
+
+	return s, nil
+}
+
+// Close closes every viewport in the system, and every bus opened by the build, returning the first error.
+// As closing a MAX7219 viewport closes its bus, this includes the buses given to the build that displays were built on.
+// The system must not be used after it is closed.
+func (s *System) Close() error {
+	var result error
+	for _, vp := range s.roots {
+		if err := viewport.Close(vp); err != nil && result == nil {
+			result = err
+		}
+	}
+	if err := s.closeBuses(); err != nil && result == nil {
+		result = err
+	}
+	return result
+}
+
+// Closes the viewports and buses opened by a build that has failed, leaving open the buses given to it.
+func (s *System) release() {
+	for _, vp := range s.drivers {
+		viewport.Close(vp)
+	}
+	s.closeBuses()
+}
+
+// Closes the buses opened by the build, returning the first error.
+func (s *System) closeBuses() error {
+	var result error
+	for name, bus := range s.Buses {
+		if _, ok := s.given[name]; ok {
+			continue
+		}
+		if c, ok := bus.(io.Closer); ok {
+			if err := c.Close(); err != nil && result == nil {
+				result = err
+			}
+		}
+	}
+	return result
+}
+
+// Builds the viewport for a display, building the displays it contains first.
+func (s *System) viewPort(c *Config, name string, displays map[string]int) (viewport.ViewPort, error) {
+	if vp, ok := s.ViewPorts[name]; ok {
+		return vp, nil
+	}
+
+	i := displays[name]
+	d := c.Displays[i]
+	field := fmt.Sprintf("displays[%d]", i)
+
+	var result viewport.ViewPort
+	switch d.Type {
+	case "max7219":
+		vp, err := max7219.FromBus(s.Buses[d.Bus]).
+			WithChainLength(d.Chain).
+			WithOrientation(orientations[d.BlockOrientation], orientations[d.ChainOrientation]).
+			Build()
+		if err != nil {
+			return nil, &FieldError{Field: field, Message: err.Error()}
+		}
+		s.drivers = append(s.drivers, vp)
+		if d.Brightness != nil {
+			vp.SetBrightness(byte(*d.Brightness))
+		}
+		result = vp
+
+	case "terminal":
+		vp, err := terminal.ToStdout().WithSize(d.Height, d.Width).WithStyle(styles[d.Style]).Build()
+		if err != nil {
+			return nil, &FieldError{Field: field, Message: err.Error()}
+		}
+		s.drivers = append(s.drivers, vp)
+		result = vp
+
+	case "composite":
+		composite := viewport.NewComposite()
+		for _, p := range d.Panels {
+			vp, err := s.viewPort(c, p.Display, displays)
+			if err != nil {
+				return nil, err
+			}
+			composite.Add(vp, p.Row, p.Col)
+		}
+		result = composite
+
+	case "broadcast":
+		broadcast := viewport.NewBroadcast()
+		for _, p := range d.Panels {
+			vp, err := s.viewPort(c, p.Display, displays)
+			if err != nil {
+				return nil, err
+			}
+			t := transform(p.Rotate, p.MirrorHorizontal, p.MirrorVertical, p.Invert)
+			if t == (viewport.Transform{}) {
+				broadcast.Add(vp)
+			} else {
+				broadcast.AddTransformed(vp, t)
+			}
+		}
+		result = broadcast
+	}
+
+	if t := transform(d.Rotate, d.MirrorHorizontal, d.MirrorVertical, d.Invert); t != (viewport.Transform{}) {
+		result = viewport.NewTransformed(result, t)
+	}
+
+	s.ViewPorts[name] = result
+	return result, nil
+}
+
+// Returns the transform for a validated rotation in degrees, mirroring and inversion.
+func transform(rotate int, mirrorHorizontal, mirrorVertical, invert bool) viewport.Transform {
+	return viewport.Transform{
+		Rotation:         viewport.Rotation(rotate / 90),
+		MirrorHorizontal: mirrorHorizontal,
+		MirrorVertical:   mirrorVertical,
+		Invert:           invert,
+	}
+}
+
+// Returns a builder for a validated bus configuration.
+func busBuilder(b Bus) *max7219.BusBuilder {
+	builder := max7219.FromDeviceName(b.Device)
+	if b.Pins != nil {
+		builder = max7219.FromPinNames(b.Pins.DIN, b.Pins.CLK, b.Pins.Load)
+	}
+	if b.ClockSpeed != "" {
+		var f physic.Frequency
+		f.Set(b.ClockSpeed)
+		builder.WithClockSpeed(f)
+	}
+	if b.Mode != nil {
+		builder.WithMode(spi.Mode(*b.Mode))
+	}
+	if b.BitsPerWord != 0 {
+		builder.WithBitsPerWord(b.BitsPerWord)
+	}
+	if b.ChipSelect != nil {
+		builder.WithChipSelect(*b.ChipSelect)
+	}
+	return builder
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Config describes a complete installation: buses, displays and canvases.
+type Config struct {
+	Buses    []Bus     `json:"buses"`
+	Displays []Display `json:"displays"`
+	Canvases []Canvas  `json:"canvases"`
+}
+
+// Bus describes an SPI bus to which MAX7219 chains are attached.
+type Bus struct {
+	Name string `json:"name"`
+
+	// Device is the SPI device name, or empty for the default device
+	Device string `json:"device"`
+
+	// Pins, if given, are the names of the GPIO pins over which the bus is bit-banged, instead of using an SPI device
+	Pins *Pins `json:"pins,omitempty"`
+
+	// Optional SPI connection parameters: a clock speed such as "4MHz", an SPI mode in the range 0..3,
+	// the number of bits per word, and the chip-select line to use on the device
+	ClockSpeed  string `json:"clockSpeed,omitempty"`
+	Mode        *int   `json:"mode,omitempty"`
+	BitsPerWord int    `json:"bitsPerWord,omitempty"`
+	ChipSelect  *int   `json:"chipSelect,omitempty"`
+}
+
+// Pins identifies the GPIO pins connected to the DIN, CLK and LOAD inputs of the first chip in a chain.
+type Pins struct {
+	DIN  string `json:"din"`
+	CLK  string `json:"clk"`
+	Load string `json:"load"`
+}
+
+// Display describes a display, which is shown on a canvas through a viewport.
+//
+// The type of a display determines which of the remaining fields are used:
+//   - "max7219" is a chain of MAX7219 8x8 modules, using Bus, Chain, BlockOrientation, ChainOrientation and Brightness
+//   - "terminal" draws on standard output, using Height, Width and Style
+//   - "composite" places the displays listed in Panels at positions within one large display
+//   - "broadcast" shows the same content on each of the displays listed in Panels, optionally transformed
+//
+// A display of any type may also be transformed, using Rotate, MirrorHorizontal, MirrorVertical and Invert, for
+// example to correct for a display mounted upside down.
+type Display struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+
+	Bus              string `json:"bus,omitempty"`
+	Chain            int    `json:"chain,omitempty"`
+	BlockOrientation string `json:"blockOrientation,omitempty"`
+	ChainOrientation string `json:"chainOrientation,omitempty"`
+	Brightness       *int   `json:"brightness,omitempty"`
+
+	Height int    `json:"height,omitempty"`
+	Width  int    `json:"width,omitempty"`
+	Style  string `json:"style,omitempty"`
+
+	Panels []Panel `json:"panels,omitempty"`
+
+	// Transform of the display: a clockwise rotation of 0, 90, 180 or 270 degrees, mirroring and inversion
+	Rotate           int  `json:"rotate,omitempty"`
+	MirrorHorizontal bool `json:"mirrorHorizontal,omitempty"`
+	MirrorVertical   bool `json:"mirrorVertical,omitempty"`
+	Invert           bool `json:"invert,omitempty"`
+}
+
+// Panel places a display within a composite or broadcast display.
+type Panel struct {
+	Display string `json:"display"`
+
+	// Position of the panel within a composite display
+	Row int `json:"row,omitempty"`
+	Col int `json:"col,omitempty"`
+
+	// Transform of the panel within a broadcast display: a clockwise rotation of 0, 90, 180 or 270 degrees, mirroring and inversion
+	Rotate           int  `json:"rotate,omitempty"`
+	MirrorHorizontal bool `json:"mirrorHorizontal,omitempty"`
+	MirrorVertical   bool `json:"mirrorVertical,omitempty"`
+	Invert           bool `json:"invert,omitempty"`
+}
+
+// Canvas describes a canvas, and the displays attached to it.
+type Canvas struct {
+	Name      string       `json:"name"`
+	Height    int          `json:"height"`
+	Width     int          `json:"width"`
+	ViewPorts []Attachment `json:"viewports"`
+}
+
+// Attachment attaches a display to a canvas at a given location.
+type Attachment struct {
+	Display string `json:"display"`
+	Row     int    `json:"row,omitempty"`
+	Col     int    `json:"col,omitempty"`
+}
+
+// FieldError is a problem with a specific field of a configuration.
+type FieldError struct {
+	// Field is the path to the field, such as "displays[1].chain"
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError lists the problems found when validating a configuration.
+type ValidationError []*FieldError
+
+func (e ValidationError) Error() string {
+	msgs := make([]string, len(e))
+	for i, f := range e {
+		msgs[i] = f.Error()
+	}
+	return "config: " + strings.Join(msgs, "; ")
+}
+
+// LoadFile reads and validates a configuration from a JSON file.
+func LoadFile(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// Load reads and validates a configuration in JSON format.
+// Returns a ValidationError if the configuration is well-formed but invalid.
+func Load(r io.Reader) (*Config, error) {
+	var c Config
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		if te, ok := err.(*json.UnmarshalTypeError); ok {
+			return nil, ValidationError{{Field: te.Field, Message: fmt.Sprintf("expected %s", te.Type)}}
+		}
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
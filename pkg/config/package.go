@@ -0,0 +1,10 @@
+// Package config describes display hardware and canvases declaratively, so that one program can drive
+// differently wired signs from a configuration file per site.
+//
+// A configuration, in JSON, lists the SPI buses in use, the displays attached to them, and the canvases
+// shown on the displays.  Displays may be combined into composite layouts, or broadcast to several panels.
+// Configurations are validated before use, and validation errors identify the offending field.
+//
+// Configurations are written in JSON alone, which the standard library parses, so that the package adds no
+// dependency beyond the periph libraries already used to drive the hardware.
+package config
@@ -0,0 +1,243 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/realency/arke/pkg/max7219"
+	"github.com/realency/arke/pkg/terminal"
+	"periph.io/x/conn/v3/physic"
+)
+
+var orientations = map[string]int{
+	"top":    0,
+	"right":  1,
+	"bottom": 2,
+	"left":   3,
+}
+
+var styles = map[string]terminal.Style{
+	"":           terminal.HalfBlocks,
+	"blocks":     terminal.Blocks,
+	"halfblocks": terminal.HalfBlocks,
+	"braille":    terminal.Braille,
+}
+
+type validator struct {
+	errs ValidationError
+}
+
+func (v *validator) fail(field, format string, args ...interface{}) {
+	v.errs = append(v.errs, &FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Validate checks that a configuration is complete and consistent.
+// Returns a ValidationError listing every problem found, or nil if there are none.
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	buses := make(map[string]bool)
+	for i, b := range c.Buses {
+		field := fmt.Sprintf("buses[%d]", i)
+		v.name(field, b.Name, buses)
+		v.bus(field, b)
+	}
+
+	displays := make(map[string]*Display)
+	for i := range c.Displays {
+		d := &c.Displays[i]
+		field := fmt.Sprintf("displays[%d]", i)
+		if v.name(field, d.Name, nil) {
+			if _, ok := displays[d.Name]; ok {
+				v.fail(field+".name", "duplicate name %q", d.Name)
+			} else {
+				displays[d.Name] = d
+			}
+		}
+	}
+
+	// Each bus may drive only one display, as a bus is not safe for use by several viewports at once
+	driven := make(map[string]string)
+
+	// Each display may be used in only one place: as a panel of another display, or attached to a canvas
+	used := make(map[string]string)
+	use := func(field, name string) {
+		if _, ok := displays[name]; !ok {
+			v.fail(field, "no display named %q", name)
+			return
+		}
+		if prev, ok := used[name]; ok {
+			v.fail(field, "display %q is already used by %s", name, prev)
+			return
+		}
+		used[name] = field
+	}
+
+	for i, d := range c.Displays {
+		field := fmt.Sprintf("displays[%d]", i)
+		if !validRotation(d.Rotate) {
+			v.fail(field+".rotate", "must be 0, 90, 180 or 270")
+		}
+		switch d.Type {
+		case "max7219":
+			v.max7219(field, d, buses, driven)
+		case "terminal":
+			if d.Height < 1 {
+				v.fail(field+".height", "must be at least 1")
+			}
+			if d.Width < 1 {
+				v.fail(field+".width", "must be at least 1")
+			}
+			if _, ok := styles[d.Style]; !ok {
+				v.fail(field+".style", "must be blocks, halfblocks or braille")
+			}
+		case "composite", "broadcast":
+			if len(d.Panels) == 0 {
+				v.fail(field+".panels", "must list at least one display")
+			}
+			for j, p := range d.Panels {
+				pf := fmt.Sprintf("%s.panels[%d]", field, j)
+				use(pf+".display", p.Display)
+				if p.Row < 0 {
+					v.fail(pf+".row", "must not be negative")
+				}
+				if p.Col < 0 {
+					v.fail(pf+".col", "must not be negative")
+				}
+				if !validRotation(p.Rotate) {
+					v.fail(pf+".rotate", "must be 0, 90, 180 or 270")
+				}
+			}
+		case "":
+			v.fail(field+".type", "is required")
+		default:
+			v.fail(field+".type", "unrecognised type %q", d.Type)
+		}
+	}
+
+	for i, d := range c.Displays {
+		if cyclic(d.Name, displays, map[string]bool{}) {
+			v.fail(fmt.Sprintf("displays[%d].panels", i), "display %q contains itself", d.Name)
+		}
+	}
+
+	canvases := make(map[string]bool)
+	for i, cv := range c.Canvases {
+		field := fmt.Sprintf("canvases[%d]", i)
+		v.name(field, cv.Name, canvases)
+		if cv.Height < 1 {
+			v.fail(field+".height", "must be at least 1")
+		}
+		if cv.Width < 1 {
+			v.fail(field+".width", "must be at least 1")
+		}
+		for j, a := range cv.ViewPorts {
+			af := fmt.Sprintf("%s.viewports[%d]", field, j)
+			use(af+".display", a.Display)
+			if a.Row < 0 || (cv.Height > 0 && a.Row >= cv.Height) {
+				v.fail(af+".row", "must be within the canvas")
+			}
+			if a.Col < 0 || (cv.Width > 0 && a.Col >= cv.Width) {
+				v.fail(af+".col", "must be within the canvas")
+			}
+		}
+	}
+
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+	return nil
+}
+
+// Checks that a name is present and, if a set of names is given, unique within it.
+func (v *validator) name(field, name string, names map[string]bool) bool {
+	if name == "" {
+		v.fail(field+".name", "is required")
+		return false
+	}
+	if names == nil {
+		return true
+	}
+	if names[name] {
+		v.fail(field+".name", "duplicate name %q", name)
+		return false
+	}
+	names[name] = true
+	return true
+}
+
+func (v *validator) bus(field string, b Bus) {
+	if b.Pins != nil {
+		if b.Device != "" {
+			v.fail(field+".pins", "cannot be given with a device")
+		}
+		if b.Pins.DIN == "" || b.Pins.CLK == "" || b.Pins.Load == "" {
+			v.fail(field+".pins", "must name the din, clk and load pins")
+		}
+		if b.Mode != nil || b.BitsPerWord != 0 || b.ChipSelect != nil {
+			v.fail(field+".pins", "cannot be given with an SPI mode, bits per word or chip select")
+		}
+	}
+	if b.ClockSpeed != "" {
+		var f physic.Frequency
+		if err := f.Set(b.ClockSpeed); err != nil || f <= 0 {
+			v.fail(field+".clockSpeed", "must be a frequency such as \"10MHz\"")
+		}
+	}
+	if b.Mode != nil && (*b.Mode < 0 || *b.Mode > 3) {
+		v.fail(field+".mode", "must be in the range 0..3")
+	}
+	if b.BitsPerWord < 0 {
+		v.fail(field+".bitsPerWord", "must be positive")
+	}
+	if b.ChipSelect != nil && *b.ChipSelect < 0 {
+		v.fail(field+".chipSelect", "must not be negative")
+	}
+}
+
+func (v *validator) max7219(field string, d Display, buses map[string]bool, driven map[string]string) {
+	if !buses[d.Bus] {
+		v.fail(field+".bus", "no bus named %q", d.Bus)
+	} else if prev, ok := driven[d.Bus]; ok {
+		v.fail(field+".bus", "bus %q is already used by %s", d.Bus, prev)
+	} else {
+		driven[d.Bus] = field
+	}
+	if d.Chain < 1 {
+		v.fail(field+".chain", "must be at least 1")
+	}
+	if o, ok := orientations[d.BlockOrientation]; !ok {
+		v.fail(field+".blockOrientation", "must be top, right, bottom or left")
+	} else if o != max7219.DigitZeroAtBottom {
+		v.fail(field+".blockOrientation", "only bottom is currently supported")
+	}
+	if o, ok := orientations[d.ChainOrientation]; !ok {
+		v.fail(field+".chainOrientation", "must be top, right, bottom or left")
+	} else if o != max7219.BlockZeroAtRight {
+		v.fail(field+".chainOrientation", "only right is currently supported")
+	}
+	if d.Brightness != nil && (*d.Brightness < 0 || *d.Brightness > 15) {
+		v.fail(field+".brightness", "must be in the range 0..15")
+	}
+}
+
+func validRotation(degrees int) bool {
+	return degrees%90 == 0 && degrees >= 0 && degrees <= 270
+}
+
+func cyclic(name string, displays map[string]*Display, visiting map[string]bool) bool {
+	d, ok := displays[name]
+	if !ok {
+		return false
+	}
+	if visiting[name] {
+		return true
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+	for _, p := range d.Panels {
+		if cyclic(p.Display, displays, visiting) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,342 @@
+package daemon
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/realency/arke/pkg/bits"
+	"github.com/realency/arke/pkg/display"
+	"github.com/realency/arke/pkg/font"
+	"github.com/realency/arke/pkg/viewport"
+	"github.com/realency/arke/pkg/widget"
+)
+
+// Errors returned when executing requests.
+var (
+	ErrUnknownOp     = errors.New("unknown operation")
+	ErrNoRegion      = errors.New("no such region")
+	ErrBadToken      = errors.New("token does not match lease")
+	ErrRegionTaken   = errors.New("region is already leased")
+	ErrOverlap       = errors.New("region overlaps a leased region")
+	ErrOutOfBounds   = errors.New("region is not within the canvas")
+	ErrNoBrightness  = errors.New("display does not support brightness")
+	ErrBadBrightness = errors.New("brightness must be in the range 0..15")
+)
+
+// SweepInterval is the interval at which expired leases are released.
+var SweepInterval = time.Second
+
+type lease struct {
+	name                    string
+	token                   string
+	row, col, height, width int
+	expires                 time.Time
+}
+
+func (l *lease) overlaps(o *lease) bool {
+	return l.row < o.row+o.height && o.row < l.row+l.height && l.col < o.col+o.width && o.col < l.col+l.width
+}
+
+// Daemon arbitrates access to a canvas, and the viewport showing it, between several clients.
+type Daemon struct {
+	mutex    sync.Mutex
+	canvas   *display.Canvas
+	viewPort viewport.ViewPort
+	font     display.Font
+	leases   map[string]*lease
+	stop     chan struct{}
+}
+
+// New returns a new instance of Daemon, managing the given canvas.
+// The viewport, which may be nil, should already be attached to the canvas; it is used to control the display's brightness.
+// The daemon releases expired leases in the background until Close is called.
+func New(canvas *display.Canvas, vp viewport.ViewPort) *Daemon {
+	d := &Daemon{
+		canvas:   canvas,
+		viewPort: vp,
+		font:     font.Standard,
+		leases:   make(map[string]*lease),
+		stop:     make(chan struct{}),
+	}
+	go d.sweep()
+	return d
+}
+
+// SetFont sets the font used to render text.
+func (d *Daemon) SetFont(f display.Font) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.font = f
+}
+
+// Close stops the daemon releasing expired leases.
+func (d *Daemon) Close() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	select {
+	case <-d.stop:
+	default:
+		close(d.stop)
+	}
+}
+
+func (d *Daemon) sweep() {
+	ticker := time.NewTicker(SweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.stop:
+			return
+		case now := <-ticker.C:
+			d.mutex.Lock()
+			d.expire(now)
+			d.mutex.Unlock()
+		}
+	}
+}
+
+func (d *Daemon) expire(now time.Time) {
+	for name, l := range d.leases {
+		if !l.expires.IsZero() && now.After(l.expires) {
+			d.clear(l)
+			delete(d.leases, name)
+		}
+	}
+}
+
+// Execute performs a request, and returns the response to send to the client.
+func (d *Daemon) Execute(req Request) Response {
+	resp, err := d.execute(req)
+	if err != nil {
+		return Response{Error: err.Error()}
+	}
+	return resp
+}
+
+func (d *Daemon) execute(req Request) (Response, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.expire(time.Now())
+
+	var err error
+	resp := Response{}
+	switch req.Op {
+	case "lease":
+		resp.Token, err = d.lease(req)
+	case "renew":
+		err = d.renew(req)
+	case "release":
+		err = d.release(req)
+	case "text":
+		err = d.text(req)
+	case "draw":
+		err = d.draw(req)
+	case "clear":
+		var l *lease
+		if l, err = d.authorise(req); err == nil {
+			d.clear(l)
+		}
+	case "brightness":
+		err = d.brightness(req)
+	case "regions":
+		resp.Regions = d.regions()
+	default:
+		err = fmt.Errorf("%w %q", ErrUnknownOp, req.Op)
+	}
+
+	if err != nil {
+		return Response{}, err
+	}
+	resp.OK = true
+	return resp, nil
+}
+
+func (d *Daemon) lease(req Request) (string, error) {
+	if _, ok := d.leases[req.Region]; ok {
+		return "", ErrRegionTaken
+	}
+	if req.Region == "" {
+		return "", errors.New("region name is required")
+	}
+
+	h, w := d.canvas.Size()
+	if req.Row < 0 || req.Col < 0 || req.Height < 1 || req.Width < 1 || req.Row+req.Height > h || req.Col+req.Width > w {
+		return "", ErrOutOfBounds
+	}
+
+	l := &lease{
+		name:   req.Region,
+		row:    req.Row,
+		col:    req.Col,
+		height: req.Height,
+		width:  req.Width,
+	}
+	for _, o := range d.leases {
+		if l.overlaps(o) {
+			return "", fmt.Errorf("%w %q", ErrOverlap, o.name)
+		}
+	}
+
+	expires, err := expiry(req.TTL)
+	if err != nil {
+		return "", err
+	}
+	l.expires = expires
+
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	l.token = hex.EncodeToString(token)
+
+	d.leases[l.name] = l
+	return l.token, nil
+}
+
+func expiry(ttl string) (time.Time, error) {
+	if ttl == "" {
+		return time.Time{}, nil
+	}
+	dur, err := time.ParseDuration(ttl)
+	if err != nil || dur <= 0 {
+		return time.Time{}, fmt.Errorf("invalid ttl %q", ttl)
+	}
+	return time.Now().Add(dur), nil
+}
+
+func (d *Daemon) authorise(req Request) (*lease, error) {
+	l, ok := d.leases[req.Region]
+	if !ok {
+		return nil, ErrNoRegion
+	}
+	if l.token != req.Token {
+		return nil, ErrBadToken
+	}
+	return l, nil
+}
+
+func (d *Daemon) renew(req Request) error {
+	l, err := d.authorise(req)
+	if err != nil {
+		return err
+	}
+	if req.TTL == "" {
+		return nil
+	}
+	expires, err := expiry(req.TTL)
+	if err != nil {
+		return err
+	}
+	l.expires = expires
+	return nil
+}
+
+func (d *Daemon) release(req Request) error {
+	l, err := d.authorise(req)
+	if err != nil {
+		return err
+	}
+	d.clear(l)
+	delete(d.leases, l.name)
+	return nil
+}
+
+func (d *Daemon) clear(l *lease) {
+	d.canvas.Write(bits.NewMatrix(l.height, l.width), l.row, l.col)
+}
+
+func (d *Daemon) text(req Request) error {
+	l, err := d.authorise(req)
+	if err != nil {
+		return err
+	}
+
+	label := widget.NewLabel(d.font, req.Text)
+	switch req.Align {
+	case "", "left":
+	case "center":
+		label.SetAlignment(widget.AlignCenter)
+	case "right":
+		label.SetAlignment(widget.AlignRight)
+	default:
+		return fmt.Errorf("invalid alignment %q", req.Align)
+	}
+	label.Mount(d.canvas, widget.Rect{Row: l.row, Col: l.col, Height: l.height, Width: l.width})
+	return nil
+}
+
+func (d *Daemon) draw(req Request) error {
+	l, err := d.authorise(req)
+	if err != nil {
+		return err
+	}
+	if req.Row < 0 || req.Col < 0 || req.Row >= l.height || req.Col >= l.width {
+		return ErrOutOfBounds
+	}
+
+	width := 0
+	for _, p := range req.Pixels {
+		if n := len([]rune(p)); n > width {
+			width = n
+		}
+	}
+	if len(req.Pixels) == 0 || width == 0 {
+		return nil
+	}
+
+	// Pixels are drawn over the current content of the region, clipped to the region's extent
+	m := bits.NewMatrix(len(req.Pixels), width)
+	for i, p := range req.Pixels {
+		for j, r := range []rune(p) {
+			switch r {
+			case '#', '@', '*', 'X', '1':
+				m.Set(i, j, true)
+			}
+		}
+	}
+	region := bits.NewMatrix(l.height, l.width)
+	bits.Copy(d.canvas.Matrix(), l.row, l.col, region, 0, 0, l.height, l.width)
+	bits.Copy(m, 0, 0, region, req.Row, req.Col, len(req.Pixels), width)
+	d.canvas.Write(region, l.row, l.col)
+	return nil
+}
+
+// The display is shared, so brightness may be set by any client holding a lease, as it may be drawn on.
+func (d *Daemon) brightness(req Request) error {
+	if _, err := d.authorise(req); err != nil {
+		return err
+	}
+	if !viewport.SupportsBrightness(d.viewPort) {
+		return ErrNoBrightness
+	}
+	if req.Level < 0 || req.Level > 15 {
+		return ErrBadBrightness
+	}
+	viewport.SetBrightness(d.viewPort, byte(req.Level))
+	return nil
+}
+
+func (d *Daemon) regions() []RegionInfo {
+	result := make([]RegionInfo, 0, len(d.leases))
+	for _, l := range d.leases {
+		info := RegionInfo{
+			Name:   l.name,
+			Row:    l.row,
+			Col:    l.col,
+			Height: l.height,
+			Width:  l.width,
+		}
+		if !l.expires.IsZero() {
+			info.Expires = l.expires.Format(time.RFC3339)
+		}
+		result = append(result, info)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
@@ -0,0 +1,6 @@
+// Package daemon allows several processes to share a single display through a local network API.
+//
+// A Daemon owns a display.Canvas and the viewport that shows it.  Clients lease named, non-overlapping
+// regions of the canvas, and may draw only within the regions they hold.  Commands are accepted as
+// newline-delimited JSON over a stream listener, such as a Unix socket, and as JSON over HTTP.
+package daemon
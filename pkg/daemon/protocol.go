@@ -0,0 +1,55 @@
+package daemon
+
+// Request is a command sent to the daemon.
+//
+// The operation determines which of the remaining fields are used:
+//   - "lease" claims a region, given its name, location, size and optionally a time-to-live
+//   - "renew" extends the lease on a region, given its name, token and optionally a new time-to-live
+//   - "release" gives up a region, given its name and token, and clears it
+//   - "text" writes text into a region, given its name, token, text and optionally an alignment
+//   - "draw" sets pixels in a region, given its name, token, pixel rows and optionally an offset into the region
+//   - "clear" clears a region, given its name and token
+//   - "brightness" sets the brightness of the whole display, given the name and token of any leased region, and a
+//     level in the range 0..15
+//   - "regions" lists the regions currently leased
+type Request struct {
+	Op     string `json:"op"`
+	Region string `json:"region,omitempty"`
+	Token  string `json:"token,omitempty"`
+
+	// Location and size of a region, for "lease", or an offset into the region, for "draw"
+	Row    int `json:"row,omitempty"`
+	Col    int `json:"col,omitempty"`
+	Height int `json:"height,omitempty"`
+	Width  int `json:"width,omitempty"`
+
+	// TTL is the lifetime of a lease, as a Go duration string such as "30s".  Empty for a lease that does not expire.
+	TTL string `json:"ttl,omitempty"`
+
+	// Text and its alignment within the region: "left", "center" or "right"
+	Text  string `json:"text,omitempty"`
+	Align string `json:"align,omitempty"`
+
+	// Pixels holds one string per row of pixels, in which '#', '@', '*', 'X' and '1' are lit, and any other character is unlit
+	Pixels []string `json:"pixels,omitempty"`
+
+	Level int `json:"level,omitempty"`
+}
+
+// Response is the daemon's reply to a Request.
+type Response struct {
+	OK      bool         `json:"ok"`
+	Error   string       `json:"error,omitempty"`
+	Token   string       `json:"token,omitempty"`
+	Regions []RegionInfo `json:"regions,omitempty"`
+}
+
+// RegionInfo describes a leased region.
+type RegionInfo struct {
+	Name    string `json:"name"`
+	Row     int    `json:"row"`
+	Col     int    `json:"col"`
+	Height  int    `json:"height"`
+	Width   int    `json:"width"`
+	Expires string `json:"expires,omitempty"`
+}
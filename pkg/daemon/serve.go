@@ -0,0 +1,102 @@
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Serve accepts connections on a listener, such as a Unix socket, and executes the requests received on each.
+//
+// Each connection carries a sequence of requests, each encoded as a single line of JSON.  A response is written,
+// as a single line of JSON, for each request.  Serve returns when the listener fails, for example when it is closed.
+func (d *Daemon) Serve(l net.Listener) error {
+	for {
+		cx, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go d.serveConn(cx)
+	}
+}
+
+func (d *Daemon) serveConn(cx net.Conn) {
+	defer cx.Close()
+
+	scanner := bufio.NewScanner(cx)
+	enc := json.NewEncoder(cx)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var req Request
+		var resp Response
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			resp.Error = "invalid request: " + err.Error()
+		} else {
+			resp = d.Execute(req)
+		}
+		if err := enc.Encode(resp); err != nil {
+			return
+		}
+	}
+}
+
+// ServeHTTP executes requests received over HTTP.
+//
+// The operation is taken from the last element of the URL path, so that, for example, a lease is requested
+// by a POST to /api/lease.  The remaining fields of the request are read from a JSON body.  The list of
+// regions may also be requested by a GET to /api/regions.
+func (d *Daemon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req Request
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			writeJSON(w, http.StatusBadRequest, Response{Error: "invalid request: " + err.Error()})
+			return
+		}
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, Response{Error: "method not allowed"})
+		return
+	}
+
+	req.Op = r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	if r.Method == http.MethodGet && req.Op != "regions" {
+		writeJSON(w, http.StatusMethodNotAllowed, Response{Error: "method not allowed"})
+		return
+	}
+
+	resp, err := d.execute(req)
+	if err != nil {
+		writeJSON(w, httpStatus(err), Response{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func httpStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrUnknownOp), errors.Is(err, ErrNoRegion):
+		return http.StatusNotFound
+	case errors.Is(err, ErrBadToken):
+		return http.StatusForbidden
+	case errors.Is(err, ErrRegionTaken), errors.Is(err, ErrOverlap):
+		return http.StatusConflict
+	case errors.Is(err, ErrNoBrightness):
+		return http.StatusNotImplemented
+	}
+	return http.StatusBadRequest
+}
+
+func writeJSON(w http.ResponseWriter, status int, resp Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
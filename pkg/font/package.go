@@ -0,0 +1,3 @@
+// Package font provides bitmap fonts for rendering text on dot-matrix displays.
+// Fonts are provided as values of type display.Font.
+package font
@@ -0,0 +1,33 @@
+package font
+
+import (
+	"github.com/realency/arke/pkg/bits"
+	"github.com/realency/arke/pkg/display"
+)
+
+// Render returns a line of text rendered in a given font, with the glyphs laid side by side from the left.
+// The line is as wide as its glyphs together, and as high as the tallest of them.
+func Render(f display.Font, text string) *bits.Matrix {
+	glyphs := make([]*bits.Matrix, 0, len(text))
+	height, width := 0, 0
+	for _, r := range text {
+		g := f(r)
+		h, w := g.Size()
+		if h > height {
+			height = h
+		}
+		glyphs = append(glyphs, g)
+		width += w
+	}
+
+	result := bits.NewMatrix(height, width)
+	col := 0
+	for _, g := range glyphs {
+		h, w := g.Size()
+		if h > 0 && w > 0 {
+			bits.Copy(g, 0, 0, result, 0, col, h, w)
+		}
+		col += w
+	}
+	return result
+}
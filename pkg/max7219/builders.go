@@ -1,19 +1,38 @@
 package max7219
 
 import (
+	"fmt"
+	"io"
+	"strings"
+
 	"periph.io/x/conn/v3"
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/gpio/gpioreg"
 	"periph.io/x/conn/v3/physic"
 	"periph.io/x/conn/v3/spi"
 	"periph.io/x/conn/v3/spi/spireg"
 	"periph.io/x/host/v3"
 )
 
+// Default SPI connection parameters, used unless overridden on the BusBuilder.
+const (
+	DefaultClockSpeed  physic.Frequency = physic.MegaHertz * 10
+	DefaultMode        spi.Mode         = spi.Mode3
+	DefaultBitsPerWord int              = 8
+)
+
 // BusBuilder is a builder type for creating a Bus in a fluent programming style.
 type BusBuilder struct {
-	dev  string
-	port spi.Port
-	cx   conn.Conn
-	bus  Bus
+	dev         string
+	chipSelect  int
+	port        spi.Port
+	pins        []gpio.PinOut
+	pinNames    []string
+	cx          conn.Conn
+	bus         Bus
+	clockSpeed  physic.Frequency
+	mode        spi.Mode
+	bitsPerWord int
 }
 
 // ViewPortBuilder is a builder type for creating a ViewPort in a fluent programming style.
@@ -24,6 +43,13 @@ type ViewPortBuilder struct {
 	chainLength      int
 }
 
+// SevenSegmentBuilder is a builder type for creating a SevenSegment display in a fluent programming style.
+type SevenSegmentBuilder struct {
+	bus           *BusBuilder
+	chainLength   int
+	digitsPerChip int
+}
+
 // FromScratch creates a new BusBuilder appropriate for building a bus from scratch using the default SPI device.
 func FromScratch() *BusBuilder {
 	return FromDeviceName("")
@@ -32,14 +58,43 @@ func FromScratch() *BusBuilder {
 // FromDeviceName creates a new BusBuilder appropriate for building a bus using a specific SPI device, identified by device name.
 func FromDeviceName(dev string) *BusBuilder {
 	return &BusBuilder{
-		dev: dev,
+		dev:         dev,
+		chipSelect:  -1,
+		clockSpeed:  DefaultClockSpeed,
+		mode:        DefaultMode,
+		bitsPerWord: DefaultBitsPerWord,
 	}
 }
 
 // FromSpiPort creates a new BusBuilder appropriate for building a bus using a pre-created SPI Port.
 func FromSpiPort(port spi.Port) *BusBuilder {
 	return &BusBuilder{
-		port: port,
+		port:        port,
+		chipSelect:  -1,
+		clockSpeed:  DefaultClockSpeed,
+		mode:        DefaultMode,
+		bitsPerWord: DefaultBitsPerWord,
+	}
+}
+
+// FromPins creates a new BusBuilder appropriate for building a bus that bit-bangs data over three GPIO pins,
+// connected to the DIN, CLK and LOAD (or CS) inputs of the first chip in the chain.
+// This is useful where the hardware SPI ports are not available.  The bit rate is set using WithClockSpeed.
+func FromPins(din, clk, load gpio.PinOut) *BusBuilder {
+	return &BusBuilder{
+		pins:       []gpio.PinOut{din, clk, load},
+		chipSelect: -1,
+		clockSpeed: DefaultClockSpeed,
+	}
+}
+
+// FromPinNames creates a new BusBuilder appropriate for building a bus that bit-bangs data over three GPIO pins,
+// identified by name, as for FromPins.
+func FromPinNames(din, clk, load string) *BusBuilder {
+	return &BusBuilder{
+		pinNames:   []string{din, clk, load},
+		chipSelect: -1,
+		clockSpeed: DefaultClockSpeed,
 	}
 }
 
@@ -57,6 +112,51 @@ func FromBus(bus Bus) *BusBuilder {
 	}
 }
 
+// WithClockSpeed specifies the SPI clock speed, and returns the BusBuilder.
+// Lower speeds may be needed for long cable runs.  The MAX7219 supports speeds up to 10MHz.
+// For a bus on GPIO pins, this is the maximum bit rate; the actual rate is limited by the speed of the pins.
+func (b *BusBuilder) WithClockSpeed(speed physic.Frequency) *BusBuilder {
+	b.clockSpeed = speed
+	return b
+}
+
+// WithMode specifies the SPI mode, and returns the BusBuilder.
+func (b *BusBuilder) WithMode(mode spi.Mode) *BusBuilder {
+	b.mode = mode
+	return b
+}
+
+// WithBitsPerWord specifies the number of bits per SPI word, and returns the BusBuilder.
+func (b *BusBuilder) WithBitsPerWord(bits int) *BusBuilder {
+	b.bitsPerWord = bits
+	return b
+}
+
+// WithChipSelect specifies the chip-select line of the SPI bus to which the chain is attached, and returns the BusBuilder.
+//
+// The chip select is combined with the device name: for example, chip select 1 on device "SPI0" opens "SPI0.1".
+// If no device name is given, chip select 1 opens "SPI0.1".  The chip select should not be specified if the
+// device name already identifies one, nor for a bus built from a pre-created port or from GPIO pins: Build
+// returns an error in each case.
+func (b *BusBuilder) WithChipSelect(cs int) *BusBuilder {
+	b.chipSelect = cs
+	return b
+}
+
+// Returns the name of the SPI port to open, taking account of any chip select.
+func (b *BusBuilder) portName() (string, error) {
+	if b.chipSelect < 0 {
+		return b.dev, nil
+	}
+	if b.dev == "" {
+		return fmt.Sprintf("SPI0.%d", b.chipSelect), nil
+	}
+	if strings.ContainsRune(b.dev, '.') {
+		return "", fmt.Errorf("max7219: chip select %d specified for device %q, which already identifies a chip select", b.chipSelect, b.dev)
+	}
+	return fmt.Sprintf("%s.%d", b.dev, b.chipSelect), nil
+}
+
 // Build builds a Bus using the configuration supplied to the builder, or returns an error if the configuration is incomplete.
 func (b *BusBuilder) Build() (Bus, error) {
 	var err error
@@ -66,21 +166,79 @@ func (b *BusBuilder) Build() (Bus, error) {
 	}
 
 	if b.cx != nil {
-		return newBus(b.cx), nil
+		return newBus(b.cx, nil), nil
+	}
+
+	if b.clockSpeed <= 0 {
+		return nil, fmt.Errorf("max7219: invalid SPI clock speed %s", b.clockSpeed)
+	}
+
+	// A chip select only chooses which SPI port to open, so has no meaning for a port or pins supplied
+	if b.chipSelect >= 0 && b.port != nil {
+		return nil, fmt.Errorf("max7219: chip select %d specified for a pre-created SPI port", b.chipSelect)
+	}
+	if b.chipSelect >= 0 && (b.pins != nil || b.pinNames != nil) {
+		return nil, fmt.Errorf("max7219: chip select %d specified for a bus on GPIO pins", b.chipSelect)
 	}
 
+	if b.pins != nil || b.pinNames != nil {
+		return b.buildPinBus()
+	}
+
+	if b.bitsPerWord < 1 {
+		return nil, fmt.Errorf("max7219: invalid SPI bits per word %d", b.bitsPerWord)
+	}
+
+	// A port opened by the builder is closed with the bus; a port supplied to the builder belongs to the caller
+	var closer io.Closer
 	if b.port == nil {
-		host.Init()
-		if b.port, err = spireg.Open(b.dev); err != nil {
+		name, err := b.portName()
+		if err != nil {
 			return nil, err
 		}
+		if _, err := host.Init(); err != nil {
+			return nil, fmt.Errorf("max7219: initialising host: %w", err)
+		}
+		port, err := spireg.Open(name)
+		if err != nil {
+			return nil, fmt.Errorf("max7219: opening SPI port %q: %w", name, err)
+		}
+		b.port, closer = port, port
+	}
+
+	if b.cx, err = b.port.Connect(b.clockSpeed, b.mode, b.bitsPerWord); err != nil {
+		if closer != nil {
+			closer.Close()
+			b.port = nil
+		}
+		return nil, fmt.Errorf("max7219: connecting to SPI port at %s, %s, %d bits per word: %w", b.clockSpeed, b.mode, b.bitsPerWord, err)
+	}
+
+	return newBus(b.cx, closer), nil
+}
+
+// Builds a bus that bit-bangs data over GPIO pins.
+func (b *BusBuilder) buildPinBus() (Bus, error) {
+	if b.pins == nil {
+		if _, err := host.Init(); err != nil {
+			return nil, fmt.Errorf("max7219: initialising host: %w", err)
+		}
+		for _, name := range b.pinNames {
+			p := gpioreg.ByName(name)
+			if p == nil {
+				return nil, fmt.Errorf("max7219: no GPIO pin named %q", name)
+			}
+			b.pins = append(b.pins, p)
+		}
 	}
 
-	if b.cx, err = b.port.Connect(physic.MegaHertz*10, spi.Mode3, 8); err != nil {
+	cx, err := newPinConn(b.pins[0], b.pins[1], b.pins[2], b.clockSpeed)
+	if err != nil {
 		return nil, err
 	}
+	b.cx = cx
 
-	return newBus(b.cx), nil
+	return newBus(b.cx, nil), nil
 }
 
 // WithChainLength specifies the chain length for the bus and returns a ViewPortBuilder.
@@ -100,6 +258,10 @@ func (v *ViewPortBuilder) WithOrientation(blockOrientation, chainOrientation int
 
 // Build builds the viewport, ready to be attached to a canvas.
 func (v *ViewPortBuilder) Build() (*ViewPort, error) {
+	if v.chainLength < 1 {
+		return nil, fmt.Errorf("max7219: invalid chain length %d", v.chainLength)
+	}
+
 	b, err := v.bus.Build()
 	if err != nil {
 		return nil, err
@@ -107,3 +269,29 @@ func (v *ViewPortBuilder) Build() (*ViewPort, error) {
 
 	return newViewPort(b, v.chainLength, v.blockOrientation, v.chainOrientation), nil
 }
+
+// WithDigits specifies the chain length for the bus, and the number of 7-segment digits on each module, and returns a SevenSegmentBuilder.
+func (b *BusBuilder) WithDigits(chainLength, digitsPerChip int) *SevenSegmentBuilder {
+	return &SevenSegmentBuilder{
+		bus:           b,
+		chainLength:   chainLength,
+		digitsPerChip: digitsPerChip,
+	}
+}
+
+// Build builds the 7-segment display, which is initially blank.
+func (s *SevenSegmentBuilder) Build() (*SevenSegment, error) {
+	if s.chainLength < 1 {
+		return nil, fmt.Errorf("max7219: invalid chain length %d", s.chainLength)
+	}
+	if s.digitsPerChip < 1 || s.digitsPerChip > 8 {
+		return nil, fmt.Errorf("max7219: invalid number of digits per chip %d, must be in the range 1..8", s.digitsPerChip)
+	}
+
+	b, err := s.bus.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return newSevenSegment(b, s.chainLength, s.digitsPerChip), nil
+}
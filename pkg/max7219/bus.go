@@ -1,6 +1,12 @@
 package max7219
 
-import "periph.io/x/conn/v3"
+import (
+	"context"
+	"io"
+	"sync"
+
+	"periph.io/x/conn/v3"
+)
 
 // Bus provides structured access to a MAX7219 chip, or chain of cascaded chips attached on a serial port.
 type Bus interface {
@@ -8,24 +14,42 @@ type Bus interface {
 	Send()
 }
 
+// bus writes packets to a connection from its own goroutine, recording any error in writing.
+// If the bus opened the port underlying the connection, it holds the port as a closer, to be closed with the bus.
 type bus struct {
-	buff []byte
-	wire chan []byte
+	buff   []byte
+	wire   chan []byte
+	done   chan struct{}
+	closer io.Closer
+	once   sync.Once
+	mutex  sync.Mutex
+	err    error
 }
 
-func newBus(cx conn.Conn) Bus {
-	wire := make(chan []byte)
+func newBus(cx conn.Conn, closer io.Closer) Bus {
+	result := &bus{
+		buff:   make([]byte, 0, 1024),
+		wire:   make(chan []byte),
+		done:   make(chan struct{}),
+		closer: closer,
+	}
 
 	go func() {
-		for {
-			cx.Tx(<-wire, nil)
+		defer close(result.done)
+		for packet := range result.wire {
+			// An empty packet is sent by Flush, and is received only once the previous packet has been written
+			if len(packet) == 0 {
+				continue
+			}
+			if err := cx.Tx(packet, nil); err != nil {
+				result.mutex.Lock()
+				result.err = err
+				result.mutex.Unlock()
+			}
 		}
 	}()
 
-	return &bus{
-		buff: make([]byte, 0, 1024),
-		wire: wire,
-	}
+	return result
 }
 
 func (b *bus) Add(reg Register, data byte) {
@@ -36,3 +60,43 @@ func (b *bus) Send() {
 	b.wire <- b.buff
 	b.buff = nil
 }
+
+// Flush blocks until every packet sent has been written, or until the context is done.
+func (b *bus) Flush(ctx context.Context) error {
+	select {
+	case b.wire <- nil:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// FlushBus blocks until every packet sent on a bus has been transmitted, or until the context is done, in which
+// case it returns the error of the context.  Buses that transmit synchronously are not affected.
+func FlushBus(ctx context.Context, bus Bus) error {
+	if f, ok := bus.(interface{ Flush(context.Context) error }); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+// Err returns the most recent error in writing to the connection, or nil if there has been none.
+func (b *bus) Err() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.err
+}
+
+// Close waits for the last packet sent to be written, and closes the port underlying the connection, if the bus opened it.
+// Closing a bus more than once has no further effect.
+func (b *bus) Close() error {
+	var err error
+	b.once.Do(func() {
+		close(b.wire)
+		<-b.done
+		if b.closer != nil {
+			err = b.closer.Close()
+		}
+	})
+	return err
+}
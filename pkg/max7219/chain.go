@@ -0,0 +1,98 @@
+package max7219
+
+import "fmt"
+
+// Chain provides register-level access to the individual chips of a chain of cascaded MAX7219 chips on a Bus.
+//
+// Chips are indexed in the order of the address-byte pairs in a packet: chip zero is controlled by the first pair sent.
+// A write to a single chip sends NoOpRegister to every other chip in the chain, leaving them unchanged.
+//
+// A Chain is not safe for concurrent use, and should not share a bus with a ViewPort; use the per-chip methods
+// of ViewPort to control the chips of a chain driven by a ViewPort.
+type Chain struct {
+	bus    Bus
+	length int
+}
+
+// NewChain creates a Chain of a given number of chips on a bus.
+func NewChain(bus Bus, length int) *Chain {
+	if length < 1 {
+		panic("Chain length must be at least 1")
+	}
+	return &Chain{
+		bus:    bus,
+		length: length,
+	}
+}
+
+// Length returns the number of chips in the chain.
+func (c *Chain) Length() int {
+	return c.length
+}
+
+// Write writes a value to a register of a single chip.
+//
+// Panics if the chip index is out of range.
+func (c *Chain) Write(chip int, reg Register, data byte) {
+	if chip < 0 || chip >= c.length {
+		panic(fmt.Sprintf("Chip index %d out of range. The chain has %d chips", chip, c.length))
+	}
+	for i := 0; i < c.length; i++ {
+		if i == chip {
+			c.bus.Add(reg, data)
+		} else {
+			c.bus.Add(NoOpRegister, 0x00)
+		}
+	}
+	c.bus.Send()
+}
+
+// Broadcast writes the same value to a register of every chip.
+func (c *Chain) Broadcast(reg Register, data byte) {
+	for i := 0; i < c.length; i++ {
+		c.bus.Add(reg, data)
+	}
+	c.bus.Send()
+}
+
+// WriteEach writes a value to a register of each chip, in a single packet.  The values are given in order of chip index.
+//
+// Panics if the number of values differs from the length of the chain.
+func (c *Chain) WriteEach(reg Register, data []byte) {
+	if len(data) != c.length {
+		panic(fmt.Sprintf("%d values given for a chain of %d chips", len(data), c.length))
+	}
+	for _, d := range data {
+		c.bus.Add(reg, d)
+	}
+	c.bus.Send()
+}
+
+// SetIntensity sets the intensity of a single chip, in the range 0..15.
+func (c *Chain) SetIntensity(chip int, intensity int) {
+	c.Write(chip, IntensityRegister, Intensity(intensity))
+}
+
+// SetShutdown puts a single chip into, or takes it out of, shutdown mode.  A chip in shutdown mode is blank,
+// but retains the content of its registers.
+func (c *Chain) SetShutdown(chip int, shutdown bool) {
+	data := NoShutdown
+	if shutdown {
+		data = Shutdown
+	}
+	c.Write(chip, ShutdownRegister, data)
+}
+
+// SetScanLimit sets the scan limit of a single chip, in the range 0..7.  See ScanLimit.
+func (c *Chain) SetScanLimit(chip int, limit int) {
+	c.Write(chip, ScanLimitRegister, ScanLimit(limit))
+}
+
+// SetDisplayTest turns display-test mode on or off for a single chip.  In display-test mode, every LED is lit at full intensity.
+func (c *Chain) SetDisplayTest(chip int, test bool) {
+	data := NoDisplayTest
+	if test {
+		data = DisplayTest
+	}
+	c.Write(chip, DisplayTestRegister, data)
+}
@@ -0,0 +1,88 @@
+package max7219
+
+import (
+	"fmt"
+	"time"
+
+	"periph.io/x/conn/v3"
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/physic"
+)
+
+// pinConn is a write-only connection that bit-bangs data to a chain of MAX7219 chips over three GPIO pins.
+//
+// Each transaction takes LOAD low, shifts every byte out on DIN most significant bit first, with the MAX7219
+// sampling DIN on each rising edge of CLK, and then takes LOAD high to latch the last 16 bits shifted into each chip.
+type pinConn struct {
+	din, clk, load gpio.PinOut
+	halfPeriod     time.Duration
+}
+
+func newPinConn(din, clk, load gpio.PinOut, speed physic.Frequency) (*pinConn, error) {
+	c := &pinConn{
+		din:        din,
+		clk:        clk,
+		load:       load,
+		halfPeriod: speed.Period() / 2,
+	}
+
+	for _, p := range []struct {
+		pin   gpio.PinOut
+		level gpio.Level
+	}{{din, gpio.Low}, {clk, gpio.Low}, {load, gpio.High}} {
+		if err := p.pin.Out(p.level); err != nil {
+			return nil, fmt.Errorf("max7219: setting pin %s as output: %w", p.pin, err)
+		}
+	}
+
+	return c, nil
+}
+
+func (c *pinConn) String() string {
+	return fmt.Sprintf("max7219 GPIO bus (DIN %s, CLK %s, LOAD %s)", c.din, c.clk, c.load)
+}
+
+func (c *pinConn) Duplex() conn.Duplex {
+	return conn.Half
+}
+
+func (c *pinConn) Tx(w, r []byte) error {
+	if len(r) != 0 {
+		return fmt.Errorf("max7219: GPIO bus is write-only")
+	}
+
+	// The first failure abandons the rest of the data, but LOAD is still raised to end the transaction
+	var err error
+	out := func(p gpio.PinOut, level gpio.Level) {
+		if e := p.Out(level); e != nil && err == nil {
+			err = fmt.Errorf("max7219: writing pin %s: %w", p, e)
+		}
+	}
+
+	out(c.load, gpio.Low)
+	for _, b := range w {
+		for mask := byte(0x80); mask != 0 && err == nil; mask >>= 1 {
+			out(c.din, b&mask != 0)
+			c.wait()
+			out(c.clk, gpio.High)
+			c.wait()
+			out(c.clk, gpio.Low)
+		}
+	}
+	c.wait()
+	out(c.load, gpio.High)
+	c.wait()
+
+	return err
+}
+
+// Waits for half a clock period.  The delays involved are generally far shorter than the resolution of
+// time.Sleep, so the wait is a busy loop.
+func (c *pinConn) wait() {
+	if c.halfPeriod <= 0 {
+		return
+	}
+	deadline := time.Now().Add(c.halfPeriod)
+	for time.Now().Before(deadline) {
+	}
+}
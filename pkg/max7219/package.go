@@ -1,4 +1,5 @@
 // Package max7219 contains teh logic and values specific to driving a Max7219 chip, or chain of Max7219 chips.
 //
 // The most commonly useful type in the package is ViewPort, which is used to attach to a display.Canvas to drive a Max7219-based LED-matrix display.
+// SevenSegment drives chains of 7-segment digit modules, and Chain provides register-level access to the individual chips of a chain.
 package max7219
@@ -47,6 +47,9 @@ const (
 	CharBlank byte = 0x0F
 )
 
+// DecimalPoint is the bit of a digit register that lights the decimal point of a 7-segment digit, in either decode mode.
+const DecimalPoint byte = 0x80
+
 // Constant definitions for Shutdown mode data values.
 const (
 	Shutdown   byte = 0x00
@@ -87,7 +90,7 @@ func Intensity(intensity int) byte {
 // Using a limit argument of 0 causes only the first digit (index 0) to be displayed.  Using a limit argument of 1 causes the first and second digits to be displayed. And so forth.
 // All digits will be displayed for an argument of 7.  For a dot-matrix display, digits map to lines in the display, so scan limit affects the number of lines displayed.
 func ScanLimit(limit int) byte {
-	if limit < 0 || limit > 15 {
+	if limit < 0 || limit > 7 {
 		panic("Scan limit must be in the range 0..7, inclusively")
 	}
 	return byte(limit)
@@ -99,7 +102,7 @@ func ScanLimit(limit int) byte {
 func CodedChar(from rune, decimalPoint bool) byte {
 	var result byte
 	if decimalPoint {
-		result = 0x08
+		result = DecimalPoint
 	}
 
 	switch {
@@ -121,3 +124,16 @@ func CodedChar(from rune, decimalPoint bool) byte {
 
 	panic("Character is not representable in BCD Code B")
 }
+
+// IsCodable reports whether a rune is representable in BCD Code B, and so may be passed to CodedChar.
+func IsCodable(from rune) bool {
+	switch {
+	case from >= '0' && from <= '9':
+		return true
+	}
+	switch from {
+	case 'h', 'H', 'e', 'E', 'l', 'L', 'p', 'P', '-', ' ', '\u0000':
+		return true
+	}
+	return false
+}
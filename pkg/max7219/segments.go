@@ -0,0 +1,55 @@
+package max7219
+
+import "unicode"
+
+// Constant definitions for the segments of a 7-segment digit, when not in decode mode.
+//
+// Segments are labelled clockwise from the top, A to F, with G the middle bar:
+//
+//	 -A-
+//	F   B
+//	 -G-
+//	E   C
+//	 -D-  DP
+const (
+	SegmentA  byte = 0x40
+	SegmentB  byte = 0x20
+	SegmentC  byte = 0x10
+	SegmentD  byte = 0x08
+	SegmentE  byte = 0x04
+	SegmentF  byte = 0x02
+	SegmentG  byte = 0x01
+	SegmentDP byte = DecimalPoint
+)
+
+// Raw segment patterns, including approximations for letters that BCD Code B lacks.
+// Where only one case is given, the same pattern serves for both.
+var segmentFont = map[rune]byte{
+	'0': 0x7E, '1': 0x30, '2': 0x6D, '3': 0x79, '4': 0x33,
+	'5': 0x5B, '6': 0x5F, '7': 0x70, '8': 0x7F, '9': 0x7B,
+
+	'A': 0x77, 'b': 0x1F, 'C': 0x4E, 'c': 0x0D, 'd': 0x3D,
+	'E': 0x4F, 'F': 0x47, 'G': 0x5E, 'H': 0x37, 'h': 0x17,
+	'I': 0x06, 'J': 0x3C, 'K': 0x57, 'L': 0x0E, 'M': 0x54,
+	'n': 0x15, 'O': 0x7E, 'o': 0x1D, 'P': 0x67, 'q': 0x73,
+	'r': 0x05, 'S': 0x5B, 't': 0x0F, 'U': 0x3E, 'u': 0x1C,
+	'v': 0x1C, 'W': 0x2A, 'X': 0x37, 'y': 0x3B, 'Z': 0x6D,
+
+	' ': 0x00, '-': 0x01, '_': 0x08, '=': 0x09, '\'': 0x02,
+	'"': 0x22, '[': 0x4E, ']': 0x78, '?': 0x65, '°': 0x63,
+}
+
+// Segments returns the raw segment pattern for a rune, for use on a 7-segment display when not in decode mode.
+// Letters are approximated, and are shown in whichever case reads best.
+//
+// Returns false if the rune has no representation.
+func Segments(from rune) (byte, bool) {
+	if s, ok := segmentFont[from]; ok {
+		return s, true
+	}
+	if s, ok := segmentFont[unicode.ToUpper(from)]; ok {
+		return s, true
+	}
+	s, ok := segmentFont[unicode.ToLower(from)]
+	return s, ok
+}
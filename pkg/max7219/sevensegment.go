@@ -0,0 +1,299 @@
+package max7219
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Constant values indicating how characters are sent to a 7-segment display
+const (
+	// Characters are sent using the BCD Code B decoder of the chip where possible, and as raw segments otherwise
+	CodeB int = 0
+
+	// All characters are sent as raw segments, using the patterns given by Segments
+	RawSegments int = 1
+)
+
+// Constant values indicating the alignment of text on a 7-segment display
+const (
+	// Text is aligned with the rightmost digit, as is usual for numbers
+	AlignRight int = 0
+
+	// Text is aligned with the leftmost digit
+	AlignLeft int = 1
+)
+
+type cell struct {
+	r  rune
+	dp bool
+}
+
+// SevenSegment drives a chain of MAX7219 chips, each controlling a module of up to eight 7-segment digits.
+//
+// Digit positions are numbered from the right of the display, from zero.  Chip zero, controlled by the first address-byte
+// pair sent in a packet, is the rightmost module, and digit register 0 of each chip controls the rightmost digit of its module.
+//
+// A SevenSegment implements io.Writer, so that formatted text may be written to it directly.
+type SevenSegment struct {
+	mutex     sync.Mutex
+	bus       Bus
+	chain     *Chain
+	perChip   int
+	mode      int
+	alignment int
+	cells     []cell
+	points    []bool
+	decode    []byte
+	asleep    bool
+	closed    bool
+}
+
+func newSevenSegment(bus Bus, chainLength, digitsPerChip int) *SevenSegment {
+	if digitsPerChip < 1 || digitsPerChip > 8 {
+		panic("Digits per chip must be in the range 1..8")
+	}
+
+	result := &SevenSegment{
+		bus:     bus,
+		chain:   NewChain(bus, chainLength),
+		perChip: digitsPerChip,
+		points:  make([]bool, chainLength*digitsPerChip),
+	}
+
+	result.init()
+	return result
+}
+
+func (s *SevenSegment) init() {
+	s.chain.Broadcast(ShutdownRegister, Shutdown)
+	s.chain.Broadcast(DisplayTestRegister, NoDisplayTest)
+	s.chain.Broadcast(ScanLimitRegister, ScanLimit(s.perChip-1))
+	s.render()
+	s.chain.Broadcast(ShutdownRegister, NoShutdown)
+}
+
+// Digits returns the number of digits in the display.
+func (s *SevenSegment) Digits() int {
+	return len(s.points)
+}
+
+// SetMode sets how characters are sent to the display: CodeB or RawSegments.
+// Code B renders digits in the font of the chip, which may differ from the raw segment patterns.
+func (s *SevenSegment) SetMode(mode int) {
+	if mode != CodeB && mode != RawSegments {
+		panic("Unrecognised 7-segment mode")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.mode = mode
+	s.render()
+}
+
+// SetAlignment sets the alignment of text shorter than the display: AlignRight or AlignLeft.
+func (s *SevenSegment) SetAlignment(alignment int) {
+	if alignment != AlignRight && alignment != AlignLeft {
+		panic("Unrecognised 7-segment alignment")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.alignment = alignment
+	s.render()
+}
+
+// SetText shows text on the display, one character per digit.
+//
+// A '.' lights the decimal point of the digit before it, rather than taking a digit of its own, unless it follows
+// another '.' or starts the text.  Characters beyond the width of the display are not shown, and characters that have
+// no 7-segment representation are shown blank.
+func (s *SevenSegment) SetText(text string) {
+	var cells []cell
+	for _, r := range text {
+		if r == '.' && len(cells) > 0 && !cells[len(cells)-1].dp {
+			cells[len(cells)-1].dp = true
+			continue
+		}
+		if r == '.' {
+			cells = append(cells, cell{' ', true})
+			continue
+		}
+		cells = append(cells, cell{r, false})
+	}
+
+	if len(cells) > s.Digits() {
+		cells = cells[:s.Digits()]
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.cells = cells
+	s.render()
+}
+
+// SetInt shows an integer on the display.  Shows dashes in every digit if the number is too wide for the display.
+func (s *SevenSegment) SetInt(n int) {
+	s.setNumber(strconv.Itoa(n))
+}
+
+// SetFloat shows a number on the display, with a fixed number of decimal places.
+// Shows dashes in every digit if the number is too wide for the display.
+func (s *SevenSegment) SetFloat(f float64, decimals int) {
+	s.setNumber(strconv.FormatFloat(f, 'f', decimals, 64))
+}
+
+func (s *SevenSegment) setNumber(text string) {
+	if len(strings.Replace(text, ".", "", 1)) > s.Digits() {
+		text = strings.Repeat("-", s.Digits())
+	}
+	s.SetText(text)
+}
+
+// SetDecimalPoint lights or clears the decimal point of the digit at a given position, counting from the right from zero,
+// independently of the text shown.
+//
+// Panics if the position is out of range.
+func (s *SevenSegment) SetDecimalPoint(position int, on bool) {
+	if position < 0 || position >= s.Digits() {
+		panic("Digit position out of range")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.points[position] = on
+	s.render()
+}
+
+// Clear blanks the display, including any decimal points set by SetDecimalPoint.
+func (s *SevenSegment) Clear() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.cells = nil
+	for i := range s.points {
+		s.points[i] = false
+	}
+	s.render()
+}
+
+// SetBrightness sets the brightness of the display in the range from 0 to 15
+func (s *SevenSegment) SetBrightness(bright byte) {
+	if bright > 15 {
+		bright = 15
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.chain.Broadcast(IntensityRegister, bright)
+}
+
+// Sleep blanks the display by putting every chip in the chain into shutdown mode.  Changes made while asleep are shown on waking.
+func (s *SevenSegment) Sleep() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.chain.Broadcast(ShutdownRegister, Shutdown)
+	s.asleep = true
+}
+
+// Wake restores the display after Sleep.
+func (s *SevenSegment) Wake() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.chain.Broadcast(ShutdownRegister, NoShutdown)
+	s.asleep = false
+}
+
+// Asleep reports whether the display is asleep.
+func (s *SevenSegment) Asleep() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.asleep
+}
+
+// Close blanks the display, and releases the bus.  An SPI port opened by the builder is closed.
+// The display must not be used after it is closed, but closing it more than once has no further effect.
+func (s *SevenSegment) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	s.chain.Broadcast(ShutdownRegister, Shutdown)
+	if c, ok := s.bus.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Err returns the most recent error in writing to the display, or nil if there has been none.
+func (s *SevenSegment) Err() error {
+	if r, ok := s.bus.(interface{ Err() error }); ok {
+		return r.Err()
+	}
+	return nil
+}
+
+// Write shows the last line of text written, as for SetText.  A trailing line break is ignored.
+// Always returns the length of p and a nil error.
+func (s *SevenSegment) Write(p []byte) (int, error) {
+	text := strings.TrimRight(string(p), "\r\n")
+	if i := strings.LastIndexAny(text, "\r\n"); i >= 0 {
+		text = text[i+1:]
+	}
+	s.SetText(text)
+	return len(p), nil
+}
+
+// Sends the content of every digit register, and the decode mode of any chip for which it has changed.
+func (s *SevenSegment) render() {
+	chips := s.chain.Length()
+	decode := make([]byte, chips)
+	digits := make([][]byte, s.perChip)
+	for d := range digits {
+		digits[d] = make([]byte, chips)
+	}
+
+	for p := range s.points {
+		c := cell{' ', false}
+		if i := s.cellIndex(p); i >= 0 {
+			c = s.cells[i]
+		}
+		dp := c.dp || s.points[p]
+		chip, digit := p/s.perChip, p%s.perChip
+
+		if s.mode == CodeB && IsCodable(c.r) {
+			decode[chip] |= 1 << digit
+			digits[digit][chip] = CodedChar(c.r, dp)
+			continue
+		}
+		segments, _ := Segments(c.r)
+		if dp {
+			segments |= DecimalPoint
+		}
+		digits[digit][chip] = segments
+	}
+
+	if string(decode) != string(s.decode) {
+		s.chain.WriteEach(DecodeModeRegister, decode)
+		s.decode = decode
+	}
+	for d, data := range digits {
+		s.chain.WriteEach(DigitRegister(d), data)
+	}
+}
+
+// Returns the index of the cell shown at a digit position, or -1 if the digit is blank.
+func (s *SevenSegment) cellIndex(position int) int {
+	var i int
+	if s.alignment == AlignRight {
+		i = len(s.cells) - 1 - position
+	} else {
+		i = s.Digits() - 1 - position
+	}
+	if i < 0 || i >= len(s.cells) {
+		return -1
+	}
+	return i
+}
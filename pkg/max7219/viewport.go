@@ -1,7 +1,13 @@
 package max7219
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"log"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/realency/arke/pkg/bits"
 	"github.com/realency/arke/pkg/display"
@@ -41,24 +47,39 @@ type offset struct {
 	row, col int
 }
 
-type attachment struct {
-	offset offset
-	canvas *display.Canvas
+// FrameInfo describes a frame sent to the display by a ViewPort.
+type FrameInfo struct {
+	// Sequence is the number of the frame, counting from one for the first frame sent
+	Sequence uint64
+
+	// Started is the time at which the ViewPort began sending the frame, and Sent the time at which the frame
+	// had been transmitted to the display.
+	Started, Sent time.Time
 }
 
 // ViewPort provides an implementation of interface viewport.ViewPort specific to the Max7219 chip.
 //
 // Attaching a ViewPort to a canvas drives a Max7219-based dot matrix display from the canvas.
+// Operations are queued on a single channel, and applied in the order requested by a goroutine of the ViewPort,
+// so that, for example, a Locate requested after an Attach moves the ViewPort on the canvas just attached.
+// Use Flush to wait for operations to reach the display.  Operations requested after Close are ignored.
 type ViewPort struct {
+	mutex                   sync.Mutex
 	canvas                  *display.Canvas
+	onFrame                 func(FrameInfo)
+	frames                  uint64
 	id                      uint64
 	row, col, height, width int
 	bus                     Bus
+	chain                   *Chain
 	chainLength             int
-	offsets                 chan offset
-	brightness              chan byte
-	canvasUpdates           chan *bits.Matrix
-	attachments             chan attachment
+	asleep                  int32
+	operations              chan func()
+	canvasUpdates           chan *bits.Matrix // Replaced on each attachment, so that updates of a previous canvas are dropped
+	closed                  bool
+	closing                 sync.Once
+	closeErr                error
+	done                    chan struct{}
 }
 
 func newViewPort(bus Bus, chainLength int, blockOrientation, chainOrientation int) *ViewPort {
@@ -79,14 +100,13 @@ func newViewPort(bus Bus, chainLength int, blockOrientation, chainOrientation in
 	}
 
 	result := &ViewPort{
-		bus:           bus,
-		chainLength:   chainLength,
-		height:        height,
-		width:         width,
-		offsets:       make(chan offset),
-		brightness:    make(chan byte, 20),
-		canvasUpdates: make(chan *bits.Matrix, 20),
-		attachments:   make(chan attachment, 20),
+		bus:         bus,
+		chain:       NewChain(bus, chainLength),
+		chainLength: chainLength,
+		height:      height,
+		width:       width,
+		operations:  make(chan func(), 20),
+		done:        make(chan struct{}),
 	}
 
 	result.init()
@@ -107,48 +127,106 @@ func (vp *ViewPort) init() {
 }
 
 func (vp *ViewPort) run() {
-	for {
-		if len(vp.canvasUpdates) > 10 || len(vp.brightness) > 10 || len(vp.attachments) > 10 || len(vp.offsets) > 10 {
+	defer close(vp.done)
+	for !vp.closed {
+		if len(vp.canvasUpdates) > 10 || len(vp.operations) > 10 {
 			log.Println("WARNING ViewPort buffering operations")
 		}
 
-		if len(vp.canvasUpdates) == 20 || len(vp.brightness) == 20 || len(vp.attachments) == 20 || len(vp.offsets) == 20 {
+		if len(vp.canvasUpdates) == 20 {
 			panic("ViewPort buffer overflow")
 		}
 
 		select {
 		case c := <-vp.canvasUpdates:
 			vp.handleUpdate(c)
-		case b := <-vp.brightness:
-			vp.broadcast(IntensityRegister, b)
-		case o := <-vp.offsets:
-			if vp.canvas == nil {
-				continue
-			}
-			vp.setOffset(o)
-			vp.handleUpdate(vp.canvas.Matrix().Clone())
-		case a := <-vp.attachments:
-			if vp.canvas == a.canvas {
-				continue
-			}
-			if vp.canvas != nil {
-				vp.canvas.RemoveObserver(vp.id)
-				vp.id = 0
-				vp.row = -1
-				vp.col = -1
-				vp.canvas = nil
-			}
-			if a.canvas != nil {
-				var b *bits.Matrix
-				vp.canvas = a.canvas
-				vp.id, b = a.canvas.AddObserver(vp.canvasUpdates)
-				vp.setOffset(a.offset)
-				vp.handleUpdate(b)
-			}
+		case op := <-vp.operations:
+			op()
+		}
+	}
+}
+
+// Queues an operation, to be applied by the goroutine of the ViewPort after those already queued.
+// The operation is dropped if the ViewPort has been closed.
+func (vp *ViewPort) queue(op func()) {
+	select {
+	case vp.operations <- op:
+	case <-vp.done:
+	}
+}
+
+// Applies every queued update of the canvas, returning once none remain.
+func (vp *ViewPort) drain() {
+	for {
+		select {
+		case c := <-vp.canvasUpdates:
+			vp.handleUpdate(c)
+		default:
+			return
 		}
 	}
 }
 
+func (vp *ViewPort) handlePower(on bool) {
+	if on {
+		vp.broadcast(ShutdownRegister, NoShutdown)
+	} else {
+		vp.broadcast(ShutdownRegister, Shutdown)
+	}
+}
+
+func (vp *ViewPort) handleOffset(o offset) {
+	if vp.canvas == nil {
+		return
+	}
+	vp.setOffset(o)
+	vp.handleUpdate(vp.canvas.Matrix().Clone())
+}
+
+func (vp *ViewPort) handleAttachment(canvas *display.Canvas, o offset) {
+	if vp.canvas == canvas {
+		return
+	}
+	if vp.canvas != nil {
+		vp.canvas.RemoveObserver(vp.id)
+		vp.canvasUpdates = nil
+		vp.mutex.Lock()
+		vp.id = 0
+		vp.row = -1
+		vp.col = -1
+		vp.canvas = nil
+		vp.mutex.Unlock()
+	}
+	if canvas != nil {
+		var b *bits.Matrix
+		vp.mutex.Lock()
+		vp.canvas = canvas
+		vp.mutex.Unlock()
+		vp.canvasUpdates = make(chan *bits.Matrix, 20)
+		vp.id, b = canvas.AddObserver(vp.canvasUpdates)
+		vp.setOffset(o)
+		vp.handleUpdate(b)
+	}
+}
+
+// Detaches the ViewPort, blanks the display, and closes the bus if it can be closed.
+// The goroutine of the ViewPort stops once the operation closing it has been applied.
+func (vp *ViewPort) close() error {
+	vp.closed = true
+	if vp.canvas != nil {
+		vp.canvas.RemoveObserver(vp.id)
+		vp.mutex.Lock()
+		vp.canvas = nil
+		vp.row, vp.col = -1, -1
+		vp.mutex.Unlock()
+	}
+	vp.broadcast(ShutdownRegister, Shutdown)
+	if c, ok := vp.bus.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
 func (vp *ViewPort) setOffset(o offset) {
 	h, w := vp.canvas.Size()
 	if o.row < 0 {
@@ -163,18 +241,22 @@ func (vp *ViewPort) setOffset(o offset) {
 	if o.col+vp.width > w {
 		o.col = w - vp.width
 	}
+	vp.mutex.Lock()
 	vp.row = o.row
 	vp.col = o.col
+	vp.mutex.Unlock()
 }
 
 func (vp *ViewPort) broadcast(reg Register, data byte) {
-	for i := 0; i < vp.chainLength; i++ {
-		vp.bus.Add(reg, data)
-	}
-	vp.bus.Send()
+	vp.chain.Broadcast(reg, data)
 }
 
 func (vp *ViewPort) handleUpdate(buff *bits.Matrix) {
+	vp.mutex.Lock()
+	onFrame := vp.onFrame
+	vp.mutex.Unlock()
+
+	started := time.Now()
 	for i := 0; i < vp.height; i++ {
 		reg := DigitRegister(7 - i)
 
@@ -185,19 +267,26 @@ func (vp *ViewPort) handleUpdate(buff *bits.Matrix) {
 		}
 		vp.bus.Send()
 	}
+
+	vp.frames++
+	if onFrame != nil {
+		FlushBus(context.Background(), vp.bus)
+		onFrame(FrameInfo{
+			Sequence: vp.frames,
+			Started:  started,
+			Sent:     time.Now(),
+		})
+	}
 }
 
 // Attach attaches the ViewPort to a canvas at a specific location, so that changes in region framed by the canvas are reflected in the display.
 func (vp *ViewPort) Attach(canvas *display.Canvas, row, col int) {
-	vp.attachments <- attachment{
-		canvas: canvas,
-		offset: offset{row, col},
-	}
+	vp.queue(func() { vp.handleAttachment(canvas, offset{row, col}) })
 }
 
 // Detach detaches the ViewPort from the canvas it is currently attached to.
 func (vp *ViewPort) Detach() {
-	vp.attachments <- attachment{canvas: nil}
+	vp.queue(func() { vp.handleAttachment(nil, offset{}) })
 }
 
 // SetBrightness sets the brightness of the display in the range from 0 to 15
@@ -205,16 +294,109 @@ func (vp *ViewPort) SetBrightness(bright byte) {
 	if bright > 15 {
 		bright = 15
 	}
-	vp.brightness <- bright
+	vp.queue(func() { vp.broadcast(IntensityRegister, bright) })
+}
+
+// Sleep blanks the display by putting every chip in the chain into shutdown mode.
+// The display continues to track the canvas while asleep, so Wake restores the current content of the canvas.
+func (vp *ViewPort) Sleep() {
+	atomic.StoreInt32(&vp.asleep, 1)
+	vp.queue(func() { vp.handlePower(false) })
+}
+
+// Wake restores the display after Sleep.
+func (vp *ViewPort) Wake() {
+	atomic.StoreInt32(&vp.asleep, 0)
+	vp.queue(func() { vp.handlePower(true) })
+}
+
+// Asleep reports whether the display is asleep.
+func (vp *ViewPort) Asleep() bool {
+	return atomic.LoadInt32(&vp.asleep) != 0
+}
+
+// Close detaches the ViewPort, blanks the display, and releases the bus.  An SPI port opened by the builder is closed.
+// The ViewPort must not be used after it is closed, but closing it more than once has no further effect.
+func (vp *ViewPort) Close() error {
+	vp.closing.Do(func() {
+		reply := make(chan error, 1)
+		vp.queue(func() {
+			vp.drain()
+			reply <- vp.close()
+		})
+		<-vp.done
+		vp.closeErr = <-reply
+	})
+	return vp.closeErr
+}
+
+// Err returns the most recent error in writing to the display, or nil if there has been none.
+func (vp *ViewPort) Err() error {
+	if r, ok := vp.bus.(interface{ Err() error }); ok {
+		return r.Err()
+	}
+	return nil
+}
+
+// SetChipBrightness sets the brightness of a single 8x8 block of the display, in the range from 0 to 15.
+// Blocks are indexed as chips in the chain: see Chain.
+// Useful for calibrating blocks of differing brightness.  A subsequent call to SetBrightness sets every block alike.
+//
+// Panics if the block index is out of range.
+func (vp *ViewPort) SetChipBrightness(chip int, bright byte) {
+	if bright > 15 {
+		bright = 15
+	}
+	vp.writeChip(chip, IntensityRegister, bright)
+}
+
+// SetChipShutdown blanks a single 8x8 block of the display, or restores it.  The content of a blanked block
+// continues to track the canvas, and is shown again when the block is restored.
+//
+// Panics if the block index is out of range.
+func (vp *ViewPort) SetChipShutdown(chip int, shutdown bool) {
+	data := NoShutdown
+	if shutdown {
+		data = Shutdown
+	}
+	vp.writeChip(chip, ShutdownRegister, data)
+}
+
+// SetChipScanLimit limits the number of lines displayed by a single 8x8 block, in the range 0..7.  See ScanLimit.
+//
+// Panics if the block index or limit is out of range.
+func (vp *ViewPort) SetChipScanLimit(chip int, limit int) {
+	vp.writeChip(chip, ScanLimitRegister, ScanLimit(limit))
+}
+
+// SetChipDisplayTest turns display-test mode on or off for a single 8x8 block, lighting every LED in the block.
+//
+// Panics if the block index is out of range.
+func (vp *ViewPort) SetChipDisplayTest(chip int, test bool) {
+	data := NoDisplayTest
+	if test {
+		data = DisplayTest
+	}
+	vp.writeChip(chip, DisplayTestRegister, data)
+}
+
+func (vp *ViewPort) writeChip(chip int, reg Register, data byte) {
+	if chip < 0 || chip >= vp.chainLength {
+		panic(fmt.Sprintf("Chip index %d out of range. The chain has %d chips", chip, vp.chainLength))
+	}
+	vp.queue(func() { vp.chain.Write(chip, reg, data) })
 }
 
 // Locate repositions the ViewPort at a new position on the underlying canvas.
 func (vp *ViewPort) Locate(row, col int) {
-	vp.offsets <- offset{row, col}
+	vp.queue(func() { vp.handleOffset(offset{row, col}) })
 }
 
 // Offset returns the current location of the ViewPort - its offset into the canvas.
+// Reflects queued operations only once they have been applied; see Flush.
 func (vp *ViewPort) Offset() (row, col int) {
+	vp.mutex.Lock()
+	defer vp.mutex.Unlock()
 	return vp.row, vp.col
 }
 
@@ -224,6 +406,45 @@ func (vp *ViewPort) Size() (height, width int) {
 }
 
 // Canvas returns the attached canvas, or nil if the ViewPort is not attached to any canvas.
+// Reflects queued operations only once they have been applied; see Flush.
 func (vp *ViewPort) Canvas() *display.Canvas {
+	vp.mutex.Lock()
+	defer vp.mutex.Unlock()
 	return vp.canvas
 }
+
+// Flush blocks until every operation requested before the call has been applied and transmitted to the display,
+// or until the context is done, in which case it returns the error of the context.  Returns nil at once if the
+// ViewPort has been closed.
+func (vp *ViewPort) Flush(ctx context.Context) error {
+	flushed := make(chan struct{})
+	op := func() {
+		vp.drain()
+		FlushBus(ctx, vp.bus)
+		close(flushed)
+	}
+	select {
+	case vp.operations <- op:
+	case <-vp.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-flushed:
+		return ctx.Err()
+	case <-vp.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// OnFrame sets a function to be called after each frame has been transmitted to the display, or nil for none.
+// The function is called from the goroutine of the ViewPort, and should return promptly.  Setting a function
+// makes the ViewPort wait for each frame to be transmitted before sending the next.
+func (vp *ViewPort) OnFrame(callback func(FrameInfo)) {
+	vp.mutex.Lock()
+	defer vp.mutex.Unlock()
+	vp.onFrame = callback
+}
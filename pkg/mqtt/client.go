@@ -0,0 +1,341 @@
+package mqtt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/realency/arke/pkg/mqtt/internal/packet"
+)
+
+// ErrNotConnected is returned when publishing while the client is not connected to the broker.
+var ErrNotConnected = errors.New("mqtt: not connected")
+
+// Handler is a function called with each message received on a subscribed topic.
+type Handler func(topic string, payload []byte)
+
+// Message is an application message, used for the client's will.
+type Message struct {
+	Topic   string
+	Payload []byte
+	Retain  bool
+}
+
+// Options configures a Client.
+type Options struct {
+	// Broker is the address of the broker, as host:port
+	Broker string
+
+	ClientID string
+	Username string
+	Password string
+
+	// KeepAlive is the interval at which the client pings the broker when otherwise idle.  Defaults to 30 seconds.
+	KeepAlive time.Duration
+
+	// ReconnectDelay is the delay before the first attempt to reconnect.  Subsequent attempts back off
+	// exponentially, up to MaxReconnectDelay.  Default to 1 second and 1 minute respectively.
+	ReconnectDelay    time.Duration
+	MaxReconnectDelay time.Duration
+
+	// Will, if not nil, is published by the broker if the client disconnects unexpectedly
+	Will *Message
+}
+
+type subscription struct {
+	filter  string
+	handler Handler
+}
+
+// Client is a minimal MQTT 3.1.1 client, supporting QoS 0 only.
+//
+// Once started, the client maintains a connection to the broker in the background, reconnecting after a
+// delay whenever the connection is lost, and renewing its subscriptions on each new connection.
+type Client struct {
+	opts          Options
+	mutex         sync.Mutex
+	subscriptions []subscription
+	onConnect     []func()
+	cx            net.Conn
+	nextID        uint16
+	stop          chan struct{}
+	connected     chan struct{}
+}
+
+// NewClient returns a new instance of Client.  The client does not connect until Start is called.
+func NewClient(opts Options) *Client {
+	if opts.KeepAlive <= 0 {
+		opts.KeepAlive = 30 * time.Second
+	}
+	if opts.ReconnectDelay <= 0 {
+		opts.ReconnectDelay = time.Second
+	}
+	if opts.MaxReconnectDelay < opts.ReconnectDelay {
+		opts.MaxReconnectDelay = time.Minute
+	}
+	return &Client{
+		opts:      opts,
+		stop:      make(chan struct{}),
+		connected: make(chan struct{}),
+	}
+}
+
+// Subscribe registers a handler for messages on topics matching a filter.
+// The subscription is sent to the broker immediately if connected, and again on every reconnection.
+// Handlers are called from the client's receiving goroutine, so should not block.
+func (c *Client) Subscribe(filter string, handler Handler) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.subscriptions = append(c.subscriptions, subscription{filter, handler})
+	if c.cx != nil {
+		c.send(c.cx, c.subscribePacket([]string{filter}))
+	}
+}
+
+// OnConnect registers a function to be called each time the client connects to the broker,
+// after its subscriptions have been renewed.
+func (c *Client) OnConnect(f func()) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.onConnect = append(c.onConnect, f)
+}
+
+// Publish sends a message to the broker at QoS 0.
+// Returns ErrNotConnected if the client is not currently connected.
+func (c *Client) Publish(topic string, payload []byte, retain bool) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.cx == nil {
+		return ErrNotConnected
+	}
+	return c.send(c.cx, packet.NewPublish(topic, payload, retain))
+}
+
+// Connected returns a channel that is closed once the client first connects to the broker.
+func (c *Client) Connected() <-chan struct{} {
+	return c.connected
+}
+
+// IsConnected reports whether the client is currently connected to the broker.
+func (c *Client) IsConnected() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.cx != nil
+}
+
+// Start begins connecting to the broker in the background.
+func (c *Client) Start() {
+	go c.run()
+}
+
+// Close disconnects from the broker, and stops the client reconnecting.
+func (c *Client) Close() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	select {
+	case <-c.stop:
+		return
+	default:
+	}
+	close(c.stop)
+	if c.cx != nil {
+		c.send(c.cx, packet.Packet{Kind: packet.Disconnect})
+		c.cx.Close()
+	}
+}
+
+func (c *Client) run() {
+	delay := c.opts.ReconnectDelay
+	first := true
+	for {
+		err := c.session(func() {
+			delay = c.opts.ReconnectDelay
+			if first {
+				first = false
+				close(c.connected)
+			}
+		})
+
+		select {
+		case <-c.stop:
+			return
+		default:
+		}
+
+		log.Printf("WARNING mqtt connection to %s lost: %v; reconnecting in %v", c.opts.Broker, err, delay)
+		select {
+		case <-c.stop:
+			return
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > c.opts.MaxReconnectDelay {
+			delay = c.opts.MaxReconnectDelay
+		}
+	}
+}
+
+// Runs a single connection to the broker, returning when the connection fails.
+func (c *Client) session(onConnect func()) error {
+	cx, err := net.DialTimeout("tcp", c.opts.Broker, 10*time.Second)
+	if err != nil {
+		return err
+	}
+	defer cx.Close()
+
+	r := bufio.NewReader(cx)
+	if err := c.handshake(cx, r); err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	select {
+	case <-c.stop:
+		c.mutex.Unlock()
+		return nil
+	default:
+	}
+	c.cx = cx
+	if len(c.subscriptions) > 0 {
+		filters := make([]string, len(c.subscriptions))
+		for i, s := range c.subscriptions {
+			filters[i] = s.filter
+		}
+		c.send(cx, c.subscribePacket(filters))
+	}
+	callbacks := append([]func(){}, c.onConnect...)
+	c.mutex.Unlock()
+
+	defer func() {
+		c.mutex.Lock()
+		c.cx = nil
+		c.mutex.Unlock()
+	}()
+
+	onConnect()
+	for _, f := range callbacks {
+		f()
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go c.ping(cx, done)
+
+	for {
+		cx.SetReadDeadline(time.Now().Add(c.opts.KeepAlive * 3 / 2))
+		p, err := packet.Read(r)
+		if err != nil {
+			return err
+		}
+		switch p.Kind {
+		case packet.Publish:
+			topic, payload, err := packet.ParsePublish(p)
+			if err != nil {
+				return err
+			}
+			c.dispatch(topic, payload)
+		case packet.PingResp, packet.SubAck, packet.UnsubAck:
+		default:
+			return fmt.Errorf("mqtt: unexpected packet type %d", p.Kind)
+		}
+	}
+}
+
+func (c *Client) handshake(cx net.Conn, r *bufio.Reader) error {
+	var flags byte = 0x02 // Clean session
+	payload := packet.AppendString(nil, c.opts.ClientID)
+	if w := c.opts.Will; w != nil {
+		flags |= 0x04
+		if w.Retain {
+			flags |= 0x20
+		}
+		payload = packet.AppendString(payload, w.Topic)
+		payload = packet.AppendString(payload, string(w.Payload))
+	}
+	if c.opts.Username != "" {
+		flags |= 0x80
+		payload = packet.AppendString(payload, c.opts.Username)
+		if c.opts.Password != "" {
+			flags |= 0x40
+			payload = packet.AppendString(payload, c.opts.Password)
+		}
+	}
+
+	header := packet.AppendString(nil, "MQTT")
+	header = append(header, 4, flags)
+	header = packet.AppendUint16(header, uint16(c.opts.KeepAlive/time.Second))
+
+	cx.SetDeadline(time.Now().Add(10 * time.Second))
+	defer cx.SetDeadline(time.Time{})
+
+	if _, err := cx.Write(packet.Packet{Kind: packet.Connect, Payload: append(header, payload...)}.Encode()); err != nil {
+		return err
+	}
+
+	p, err := packet.Read(r)
+	if err != nil {
+		return err
+	}
+	if p.Kind != packet.ConnAck || len(p.Payload) != 2 {
+		return packet.ErrMalformed
+	}
+	if code := p.Payload[1]; code != 0 {
+		return fmt.Errorf("mqtt: connection refused by broker, code %d", code)
+	}
+	return nil
+}
+
+func (c *Client) ping(cx net.Conn, done chan struct{}) {
+	ticker := time.NewTicker(c.opts.KeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			c.mutex.Lock()
+			c.send(cx, packet.Packet{Kind: packet.PingReq})
+			c.mutex.Unlock()
+		}
+	}
+}
+
+func (c *Client) dispatch(topic string, payload []byte) {
+	c.mutex.Lock()
+	var handlers []Handler
+	for _, s := range c.subscriptions {
+		if packet.Match(s.filter, topic) {
+			handlers = append(handlers, s.handler)
+		}
+	}
+	c.mutex.Unlock()
+
+	for _, h := range handlers {
+		h(topic, payload)
+	}
+}
+
+// Builds a subscribe packet.  Must be called with the mutex held.
+func (c *Client) subscribePacket(filters []string) packet.Packet {
+	c.nextID++
+	if c.nextID == 0 {
+		c.nextID = 1
+	}
+	payload := packet.AppendUint16(nil, c.nextID)
+	for _, f := range filters {
+		payload = packet.AppendString(payload, f)
+		payload = append(payload, 0)
+	}
+	return packet.Packet{Kind: packet.Subscribe, Flags: 0x02, Payload: payload}
+}
+
+// Writes a packet to the connection.  Must be called with the mutex held, so that packets are not interleaved.
+func (c *Client) send(cx net.Conn, p packet.Packet) error {
+	cx.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	_, err := cx.Write(p.Encode())
+	return err
+}
@@ -0,0 +1,190 @@
+package mqtt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/realency/arke/pkg/bitmap"
+	"github.com/realency/arke/pkg/display"
+	"github.com/realency/arke/pkg/font"
+	"github.com/realency/arke/pkg/viewport"
+)
+
+// State is the state of a sign, as published by a Controller.
+type State struct {
+	Text       string `json:"text,omitempty"`
+	Image      bool   `json:"image,omitempty"`
+	Brightness *int   `json:"brightness,omitempty"`
+	Power      string `json:"power"`
+}
+
+// Controller applies messages received over MQTT to a canvas and the viewport showing it.
+//
+// The controller subscribes to the following topics under its prefix:
+//   - <prefix>/text shows the payload as text, replacing the content of the canvas
+//   - <prefix>/image shows the payload, a PBM, PNG or GIF image, replacing the content of the canvas
+//   - <prefix>/brightness sets the brightness of the display to the payload, a number in the range 0..15
+//   - <prefix>/power turns the display "on" or "off"
+//
+// After each change, the controller publishes its State as JSON to <prefix>/state, as a retained message.
+// Payloads that cannot be applied are reported as text to <prefix>/error.  Each time the client connects,
+// the controller publishes "online" to <prefix>/status; use Will to have the broker publish "offline".
+type Controller struct {
+	mutex    sync.Mutex
+	client   *Client
+	prefix   string
+	canvas   *display.Canvas
+	viewPort viewport.ViewPort
+	font     display.Font
+	state    State
+	row, col int
+	blank    *display.Canvas
+}
+
+// Will returns a message that may be used as the will of a controller's client, reporting the sign as offline.
+func Will(prefix string) *Message {
+	return &Message{
+		Topic:   prefix + "/status",
+		Payload: []byte("offline"),
+		Retain:  true,
+	}
+}
+
+// NewController returns a new instance of Controller, and subscribes to its topics using the given client.
+// The viewport should already be attached to the canvas.
+func NewController(client *Client, prefix string, canvas *display.Canvas, vp viewport.ViewPort) *Controller {
+	c := &Controller{
+		client:   client,
+		prefix:   strings.TrimSuffix(prefix, "/"),
+		canvas:   canvas,
+		viewPort: vp,
+		font:     font.Standard,
+		state:    State{Power: "on"},
+	}
+
+	client.Subscribe(c.prefix+"/text", c.handle(c.text))
+	client.Subscribe(c.prefix+"/image", c.handle(c.image))
+	client.Subscribe(c.prefix+"/brightness", c.handle(c.brightness))
+	client.Subscribe(c.prefix+"/power", c.handle(c.power))
+	client.OnConnect(func() {
+		client.Publish(c.prefix+"/status", []byte("online"), true)
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		c.publishState()
+	})
+	return c
+}
+
+// SetFont sets the font used to render text.
+func (c *Controller) SetFont(f display.Font) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.font = f
+}
+
+// State returns the current state of the sign.
+func (c *Controller) State() State {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.state
+}
+
+func (c *Controller) handle(apply func(payload []byte) error) Handler {
+	return func(topic string, payload []byte) {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		if err := apply(payload); err != nil {
+			c.client.Publish(c.prefix+"/error", []byte(fmt.Sprintf("%s: %v", topic, err)), false)
+			return
+		}
+		c.publishState()
+	}
+}
+
+func (c *Controller) publishState() {
+	b, _ := json.Marshal(c.state)
+	c.client.Publish(c.prefix+"/state", b, true)
+}
+
+func (c *Controller) text(payload []byte) error {
+	text := string(payload)
+	c.canvas.BeginUpdate()
+	defer c.canvas.EndUpdate()
+	c.canvas.Clear()
+	display.NewWriter(c.canvas, c.font, 0, 0).Write([]byte(text))
+	c.state.Text = text
+	c.state.Image = false
+	return nil
+}
+
+func (c *Controller) image(payload []byte) error {
+	img, err := bitmap.Decode(bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	if h, w := img.Size(); h == 0 || w == 0 {
+		return fmt.Errorf("image is empty")
+	}
+
+	c.canvas.BeginUpdate()
+	defer c.canvas.EndUpdate()
+	c.canvas.Clear()
+	c.canvas.Write(img, 0, 0)
+	c.state.Text = ""
+	c.state.Image = true
+	return nil
+}
+
+func (c *Controller) brightness(payload []byte) error {
+	level, err := strconv.Atoi(strings.TrimSpace(string(payload)))
+	if err != nil || level < 0 || level > 15 {
+		return fmt.Errorf("brightness must be a number in the range 0..15")
+	}
+	if !viewport.SetBrightness(c.viewPort, byte(level)) {
+		return fmt.Errorf("display does not support brightness")
+	}
+	c.state.Brightness = &level
+	return nil
+}
+
+// Turning the power off puts the display to sleep, if the viewport supports power.  Otherwise, it attaches
+// the viewport to a blank canvas, leaving the content of the canvas intact, so that it is shown again when the power
+// is turned back on.
+func (c *Controller) power(payload []byte) error {
+	sleeps := viewport.SupportsPower(c.viewPort)
+
+	switch strings.ToLower(strings.TrimSpace(string(payload))) {
+	case "on", "1", "true":
+		if c.state.Power == "on" {
+			return nil
+		}
+		if sleeps {
+			viewport.Wake(c.viewPort)
+		} else {
+			c.viewPort.Attach(c.canvas, c.row, c.col)
+		}
+		c.state.Power = "on"
+	case "off", "0", "false":
+		if c.state.Power == "off" {
+			return nil
+		}
+		if sleeps {
+			viewport.Sleep(c.viewPort)
+			c.state.Power = "off"
+			return nil
+		}
+		if c.blank == nil {
+			c.blank = display.NewCanvas(c.viewPort.Size())
+		}
+		c.row, c.col = c.viewPort.Offset()
+		c.viewPort.Attach(c.blank, 0, 0)
+		c.state.Power = "off"
+	default:
+		return fmt.Errorf("power must be on or off")
+	}
+	return nil
+}
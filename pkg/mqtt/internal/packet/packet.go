@@ -0,0 +1,210 @@
+// Package packet encodes and decodes the MQTT 3.1.1 control packets shared by the mqtt client and the
+// mqtttest broker.
+package packet
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// MQTT control packet types.
+const (
+	Connect     byte = 1
+	ConnAck     byte = 2
+	Publish     byte = 3
+	Subscribe   byte = 8
+	SubAck      byte = 9
+	Unsubscribe byte = 10
+	UnsubAck    byte = 11
+	PingReq     byte = 12
+	PingResp    byte = 13
+	Disconnect  byte = 14
+)
+
+// ErrMalformed is returned for packets that do not conform to the protocol.
+var ErrMalformed = errors.New("mqtt: malformed packet")
+
+// A Packet is a single MQTT control packet.  The flags are the low four bits of the first byte of the fixed header.
+type Packet struct {
+	Kind    byte
+	Flags   byte
+	Payload []byte
+}
+
+// Read reads a single packet.
+func Read(r *bufio.Reader) (Packet, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return Packet{}, err
+	}
+
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return Packet{}, ErrMalformed
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return Packet{}, err
+		}
+		length += int(b&0x7F) * multiplier
+		if b&0x80 == 0 {
+			break
+		}
+		multiplier *= 128
+	}
+
+	p := Packet{
+		Kind:    header >> 4,
+		Flags:   header & 0x0F,
+		Payload: make([]byte, length),
+	}
+	if _, err := io.ReadFull(r, p.Payload); err != nil {
+		return Packet{}, err
+	}
+	return p, nil
+}
+
+// Encode returns the packet's wire representation.
+func (p Packet) Encode() []byte {
+	length := len(p.Payload)
+	result := make([]byte, 0, length+5)
+	result = append(result, p.Kind<<4|p.Flags)
+	for {
+		b := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			b |= 0x80
+		}
+		result = append(result, b)
+		if length == 0 {
+			break
+		}
+	}
+	return append(result, p.Payload...)
+}
+
+// AppendString appends a length-prefixed string.
+func AppendString(b []byte, s string) []byte {
+	b = append(b, byte(len(s)>>8), byte(len(s)))
+	return append(b, s...)
+}
+
+// AppendUint16 appends a big-endian two byte integer.
+func AppendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v>>8), byte(v))
+}
+
+// Fields reads the variable header and payload of a packet.  Once a read fails, Err returns ErrMalformed, and
+// subsequent reads return zero values.
+type Fields struct {
+	buff []byte
+	err  error
+}
+
+// NewFields returns a new instance of Fields, reading the payload of a packet.
+func NewFields(p Packet) *Fields {
+	return &Fields{buff: p.Payload}
+}
+
+// Uint16 reads a big-endian two byte integer.
+func (f *Fields) Uint16() uint16 {
+	if f.err != nil || len(f.buff) < 2 {
+		f.err = ErrMalformed
+		return 0
+	}
+	v := binary.BigEndian.Uint16(f.buff)
+	f.buff = f.buff[2:]
+	return v
+}
+
+// Text reads a length-prefixed string.
+func (f *Fields) Text() string {
+	n := int(f.Uint16())
+	if f.err != nil || len(f.buff) < n {
+		f.err = ErrMalformed
+		return ""
+	}
+	s := string(f.buff[:n])
+	f.buff = f.buff[n:]
+	return s
+}
+
+// Byte reads a single byte.
+func (f *Fields) Byte() byte {
+	if f.err != nil || len(f.buff) < 1 {
+		f.err = ErrMalformed
+		return 0
+	}
+	b := f.buff[0]
+	f.buff = f.buff[1:]
+	return b
+}
+
+// Rest reads all remaining bytes.
+func (f *Fields) Rest() []byte {
+	b := f.buff
+	f.buff = nil
+	return b
+}
+
+// More reports whether bytes remain to be read, and no read has failed.
+func (f *Fields) More() bool {
+	return len(f.buff) > 0 && f.err == nil
+}
+
+// Err returns the error of the first failed read, or nil.
+func (f *Fields) Err() error {
+	return f.err
+}
+
+// NewPublish returns a new publish packet, at QoS 0.
+func NewPublish(topic string, payload []byte, retain bool) Packet {
+	p := Packet{Kind: Publish}
+	if retain {
+		p.Flags = 0x01
+	}
+	p.Payload = AppendString(make([]byte, 0, len(topic)+len(payload)+2), topic)
+	p.Payload = append(p.Payload, payload...)
+	return p
+}
+
+// ParsePublish parses a publish packet, ignoring the packet identifier present for QoS 1 and 2.
+func ParsePublish(p Packet) (topic string, payload []byte, err error) {
+	f := NewFields(p)
+	topic = f.Text()
+	if (p.Flags>>1)&0x03 != 0 {
+		f.Uint16()
+	}
+	payload = f.Rest()
+	return topic, payload, f.Err()
+}
+
+// Match reports whether a topic matches a topic filter, which may include the wildcards '+' and '#'.
+func Match(filter, topic string) bool {
+	for {
+		if filter == "#" {
+			return true
+		}
+		fl, fr, fmore := cut(filter)
+		tl, tr, tmore := cut(topic)
+		if fl != "+" && fl != tl {
+			return false
+		}
+		if !fmore || !tmore {
+			return (!fmore && !tmore) || (!tmore && fr == "#")
+		}
+		filter, topic = fr, tr
+	}
+}
+
+func cut(s string) (level, rest string, more bool) {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '/' {
+			return s[:i], s[i+1:], true
+		}
+	}
+	return s, "", false
+}
@@ -0,0 +1,191 @@
+package mqtttest
+
+import (
+	"bufio"
+	"net"
+	"sync"
+
+	"github.com/realency/arke/pkg/mqtt/internal/packet"
+)
+
+// Broker is a minimal MQTT 3.1.1 broker, supporting QoS 0 and retained messages.
+//
+// Broker is intended for development and testing, so that a sign can be exercised without an external
+// broker.  It does not implement authentication, persistence or wills.
+type Broker struct {
+	mutex    sync.Mutex
+	clients  map[*brokerClient]struct{}
+	retained map[string][]byte
+	listener net.Listener
+}
+
+type brokerClient struct {
+	cx      net.Conn
+	mutex   sync.Mutex
+	filters []string
+}
+
+func (b *brokerClient) send(p packet.Packet) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.cx.Write(p.Encode())
+}
+
+// NewBroker returns a new instance of Broker.
+func NewBroker() *Broker {
+	return &Broker{
+		clients:  make(map[*brokerClient]struct{}),
+		retained: make(map[string][]byte),
+	}
+}
+
+// Serve accepts client connections on a listener until the listener fails or the broker is closed.
+func (b *Broker) Serve(l net.Listener) error {
+	b.mutex.Lock()
+	b.listener = l
+	b.mutex.Unlock()
+
+	for {
+		cx, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go b.serveConn(cx)
+	}
+}
+
+// Close closes the broker's listener, and disconnects all clients.
+func (b *Broker) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.listener != nil {
+		b.listener.Close()
+	}
+	for c := range b.clients {
+		c.cx.Close()
+	}
+}
+
+// DisconnectAll drops the connections to all clients, without closing the broker.
+// Clients are free to reconnect.  This is useful for testing a client's recovery from a lost connection.
+func (b *Broker) DisconnectAll() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for c := range b.clients {
+		c.cx.Close()
+	}
+}
+
+func (b *Broker) serveConn(cx net.Conn) {
+	defer cx.Close()
+	r := bufio.NewReader(cx)
+
+	p, err := packet.Read(r)
+	if err != nil || p.Kind != packet.Connect {
+		return
+	}
+
+	client := &brokerClient{cx: cx}
+	client.send(packet.Packet{Kind: packet.ConnAck, Payload: []byte{0, 0}})
+
+	b.mutex.Lock()
+	b.clients[client] = struct{}{}
+	b.mutex.Unlock()
+	defer func() {
+		b.mutex.Lock()
+		delete(b.clients, client)
+		b.mutex.Unlock()
+	}()
+
+	for {
+		p, err := packet.Read(r)
+		if err != nil {
+			return
+		}
+
+		switch p.Kind {
+		case packet.Publish:
+			topic, payload, err := packet.ParsePublish(p)
+			if err != nil {
+				return
+			}
+			b.publish(topic, payload, p.Flags&0x01 != 0)
+		case packet.Subscribe:
+			b.subscribe(client, p)
+		case packet.Unsubscribe:
+			f := packet.NewFields(p)
+			id := f.Uint16()
+			client.send(packet.Packet{Kind: packet.UnsubAck, Payload: packet.AppendUint16(nil, id)})
+		case packet.PingReq:
+			client.send(packet.Packet{Kind: packet.PingResp})
+		case packet.Disconnect:
+			return
+		}
+	}
+}
+
+func (b *Broker) subscribe(client *brokerClient, p packet.Packet) {
+	f := packet.NewFields(p)
+	id := f.Uint16()
+	var filters []string
+	for f.More() {
+		filters = append(filters, f.Text())
+		f.Byte()
+	}
+	if f.Err() != nil {
+		return
+	}
+
+	ack := packet.AppendUint16(nil, id)
+	for range filters {
+		ack = append(ack, 0)
+	}
+
+	b.mutex.Lock()
+	client.mutex.Lock()
+	client.filters = append(client.filters, filters...)
+	client.mutex.Unlock()
+	var retained []packet.Packet
+	for topic, payload := range b.retained {
+		for _, filter := range filters {
+			if packet.Match(filter, topic) {
+				retained = append(retained, packet.NewPublish(topic, payload, true))
+				break
+			}
+		}
+	}
+	b.mutex.Unlock()
+
+	client.send(packet.Packet{Kind: packet.SubAck, Payload: ack})
+	for _, r := range retained {
+		client.send(r)
+	}
+}
+
+func (b *Broker) publish(topic string, payload []byte, retain bool) {
+	b.mutex.Lock()
+	if retain {
+		if len(payload) == 0 {
+			delete(b.retained, topic)
+		} else {
+			b.retained[topic] = append([]byte(nil), payload...)
+		}
+	}
+	var targets []*brokerClient
+	for c := range b.clients {
+		c.mutex.Lock()
+		for _, f := range c.filters {
+			if packet.Match(f, topic) {
+				targets = append(targets, c)
+				break
+			}
+		}
+		c.mutex.Unlock()
+	}
+	b.mutex.Unlock()
+
+	p := packet.NewPublish(topic, payload, false)
+	for _, c := range targets {
+		c.send(p)
+	}
+}
@@ -0,0 +1,3 @@
+// Package mqtttest provides a minimal embedded MQTT broker, so that an mqtt.Controller can be exercised in
+// development and tests without an external broker.
+package mqtttest
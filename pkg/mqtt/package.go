@@ -0,0 +1,12 @@
+// Package mqtt allows a sign to be controlled remotely over MQTT.
+//
+// A Controller subscribes to a set of topics under a common prefix, applies the messages it receives to a
+// display.Canvas and viewport, and publishes the resulting state, and any errors, back to the broker.
+// The package includes a minimal MQTT 3.1.1 client, supporting QoS 0 only, which reconnects automatically
+// when the connection to the broker is lost.  Package mqtttest provides a matching broker for development and testing.
+//
+// The client is implemented here, rather than taken from a third-party library, to keep arke's dependencies to
+// the periph hardware libraries.  A sign needs only to subscribe to a few topics and publish its state, for which
+// QoS 0 suffices: state is republished after every change and on reconnection, so a lost message is soon replaced.
+// Applications needing more of the protocol may drive a Controller's canvas and viewport from any other client.
+package mqtt
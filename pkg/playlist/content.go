@@ -0,0 +1,157 @@
+package playlist
+
+import (
+	"time"
+
+	"github.com/realency/arke/pkg/bits"
+	"github.com/realency/arke/pkg/clock"
+	"github.com/realency/arke/pkg/display"
+	"github.com/realency/arke/pkg/font"
+	"github.com/realency/arke/pkg/viewport"
+	"github.com/realency/arke/pkg/widget"
+)
+
+// Content is something that can be shown on a viewport.
+type Content interface {
+	// Play shows the content on a viewport, returning when the content has finished or when stop is closed.
+	// Content such as static text never finishes of its own accord, and so plays until stop is closed.
+	Play(vp viewport.ViewPort, stop <-chan struct{})
+}
+
+// Implemented by content that finishes of its own accord.
+type finite interface {
+	finite()
+}
+
+func fontOrDefault(f display.Font) display.Font {
+	if f == nil {
+		return font.Standard
+	}
+	return f
+}
+
+func clockOrDefault(c clock.Clock) clock.Clock {
+	if c == nil {
+		return clock.System
+	}
+	return c
+}
+
+// Shows a widget filling a canvas the size of the viewport until stop is closed.
+func playWidget(vp viewport.ViewPort, w widget.Widget, stop <-chan struct{}) {
+	h, wd := vp.Size()
+	canvas := display.NewCanvas(h, wd)
+	w.Mount(canvas, widget.Rect{Height: h, Width: wd})
+	vp.Attach(canvas, 0, 0)
+	<-stop
+}
+
+// Text is content showing a single line of static text.
+type Text struct {
+	Text  string
+	Align widget.Alignment
+
+	// Font used to render the text, or nil for font.Standard
+	Font display.Font
+}
+
+// Play shows the text until stop is closed.
+func (t *Text) Play(vp viewport.ViewPort, stop <-chan struct{}) {
+	l := widget.NewLabel(fontOrDefault(t.Font), t.Text)
+	l.SetAlignment(t.Align)
+	playWidget(vp, l, stop)
+}
+
+// Scroll is content showing a line of text scrolling from right to left.
+// The text scrolls in from the right-hand edge of the viewport, and finishes once it has scrolled off the left-hand edge.
+type Scroll struct {
+	Text string
+
+	// Speed in columns per second, or zero for DefaultScrollSpeed
+	Speed float64
+
+	// Font used to render the text, or nil for font.Standard
+	Font display.Font
+
+	// Clock timing the scrolling, or nil for clock.System
+	Clock clock.Clock
+}
+
+// DefaultScrollSpeed is the speed of scrolling text, in columns per second, if not otherwise specified.
+var DefaultScrollSpeed = 20.0
+
+func (s *Scroll) finite() {}
+
+// Play scrolls the text across the viewport once, or until stop is closed.
+func (s *Scroll) Play(vp viewport.ViewPort, stop <-chan struct{}) {
+	f := fontOrDefault(s.Font)
+	speed := s.Speed
+	if speed <= 0 {
+		speed = DefaultScrollSpeed
+	}
+
+	tw := 0
+	for _, r := range s.Text {
+		_, w := f(r).Size()
+		tw += w
+	}
+
+	// The text is written with a viewport's width of blank space either side, so that it scrolls in from and out to a blank display
+	h, w := vp.Size()
+	canvas := display.NewCanvas(h, tw+2*w)
+	display.NewWriter(canvas, f, 0, w).Write([]byte(s.Text))
+	vp.Attach(canvas, 0, 0)
+
+	clk := clockOrDefault(s.Clock)
+	interval := time.Duration(float64(time.Second) / speed)
+	for col := 1; col <= tw+w; col++ {
+		select {
+		case <-stop:
+			return
+		case <-clk.After(interval):
+			vp.Locate(0, col)
+		}
+	}
+}
+
+// Image is content showing a static image.
+type Image struct {
+	Image *bits.Matrix
+}
+
+// Play shows the image until stop is closed.
+func (i *Image) Play(vp viewport.ViewPort, stop <-chan struct{}) {
+	playWidget(vp, widget.NewIcon(i.Image), stop)
+}
+
+// Clock is content showing the current time.
+type Clock struct {
+	// Layout of the time, as used by time.Format
+	Layout string
+	Align  widget.Alignment
+
+	// Font used to render the time, or nil for font.Standard
+	Font display.Font
+
+	// Clock telling the time, or nil for clock.System
+	Clock clock.Clock
+}
+
+// Play shows the time until stop is closed.
+func (c *Clock) Play(vp viewport.ViewPort, stop <-chan struct{}) {
+	w := widget.NewClock(fontOrDefault(c.Font), c.Layout, clock.With(clockOrDefault(c.Clock)))
+	w.SetAlignment(c.Align)
+	w.Start(time.Second)
+	defer w.Stop()
+	playWidget(vp, w, stop)
+}
+
+// Widget is content showing an arbitrary widget, filling the viewport.
+type Widget struct {
+	Widget widget.Widget
+}
+
+// Play shows the widget until stop is closed.
+func (w *Widget) Play(vp viewport.ViewPort, stop <-chan struct{}) {
+	playWidget(vp, w.Widget, stop)
+}
@@ -0,0 +1,12 @@
+// Package playlist plays a sequence of content items, such as text, images and clocks, on a display.
+//
+// A Playlist is a list of items, each with a duration, a repeat count, and optionally a window of times
+// and days during which it may be shown.  A Scheduler plays a playlist on a viewport.ViewPort in a loop,
+// and allows priority items to interrupt the playlist, resuming the interrupted item afterwards.
+// Playlists may be loaded from JSON files, and reloaded automatically when the file changes.  Widgets kept up to
+// date by the application are passed by name to the loader, for playlist files to refer to.
+//
+// YAML playlists are not supported: arke has no YAML parser among its dependencies, and adding one for a format
+// that is a superset of JSON was not thought worthwhile.  A YAML playlist may be converted to JSON with any of the
+// usual tools.
+package playlist
@@ -0,0 +1,262 @@
+package playlist
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/realency/arke/pkg/bitmap"
+	"github.com/realency/arke/pkg/clock"
+	"github.com/realency/arke/pkg/widget"
+)
+
+// DefaultDuration is the duration of an item whose content does not finish of its own accord, if not otherwise specified.
+var DefaultDuration = 10 * time.Second
+
+// Window is a period of the day, on particular days of the week, during which an item may be shown.
+type Window struct {
+	// From and To are times of day, as offsets from midnight.  If To is earlier than From, the window spans midnight.
+	// If both are zero, the window covers the whole day.
+	From, To time.Duration
+
+	// Days on which the window applies, identified by the day on which the window starts.  If empty, the window applies every day.
+	Days []time.Weekday
+}
+
+// Contains reports whether a time falls within the window.
+func (w *Window) Contains(t time.Time) bool {
+	tod := clock.TimeOfDay(t)
+	day := t.Weekday()
+
+	if w.From != w.To {
+		if !clock.Between(tod, w.From, w.To) {
+			return false
+		}
+		if w.From > w.To && tod < w.To {
+			// Early morning belongs to a window that started the previous day
+			day = (day + 6) % 7
+		}
+	}
+
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// Item is an entry in a playlist.
+type Item struct {
+	Name    string
+	Content Content
+
+	// Duration for which the content is shown each time it is played.  If zero, content that finishes of its own
+	// accord, such as scrolling text, plays until it finishes, and other content plays for DefaultDuration.
+	Duration time.Duration
+
+	// Number of times the content is played in succession, or zero to play it once
+	Repeat int
+
+	// Window during which the item may be shown, or nil if it may be shown at any time
+	Window *Window
+}
+
+func (i *Item) eligible(t time.Time) bool {
+	return i.Window == nil || i.Window.Contains(t)
+}
+
+// Playlist is a sequence of items, played in order.
+type Playlist struct {
+	Items []Item
+}
+
+type jsonWindow struct {
+	From string   `json:"from"`
+	To   string   `json:"to"`
+	Days []string `json:"days"`
+}
+
+type jsonItem struct {
+	Name     string      `json:"name"`
+	Type     string      `json:"type"`
+	Text     string      `json:"text"`
+	Align    string      `json:"align"`
+	Speed    float64     `json:"speed"`
+	File     string      `json:"file"`
+	Layout   string      `json:"layout"`
+	Widget   string      `json:"widget"`
+	Duration string      `json:"duration"`
+	Repeat   int         `json:"repeat"`
+	Window   *jsonWindow `json:"window"`
+}
+
+type jsonPlaylist struct {
+	Items []jsonItem `json:"items"`
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+var alignments = map[string]widget.Alignment{
+	"":       widget.AlignLeft,
+	"left":   widget.AlignLeft,
+	"center": widget.AlignCenter,
+	"right":  widget.AlignRight,
+}
+
+// LoadFile reads a playlist from a JSON file, as Load does.  Image files named in the playlist are found relative to
+// the playlist file.
+func LoadFile(path string, widgets map[string]widget.Widget) (*Playlist, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f, filepath.Dir(path), widgets)
+}
+
+// Load reads a playlist in JSON format.  Image files named in the playlist are found relative to the given directory,
+// and widgets named in the playlist are found in the given map, which may be nil if the playlist shows no widgets.
+// The application remains responsible for updating the widgets, for example with the latest value of a metric.
+//
+// A playlist is an object with a single member, "items", holding an array of items.  Each item has a "type",
+// which is one of "text", "scroll", "image", "clock" or "widget", and members as follows:
+//   - "name" optionally names the item
+//   - "text" is the text shown by text and scroll items
+//   - "align" is the alignment of text and clock items: "left", "center" or "right"
+//   - "speed" is the speed of scroll items, in columns per second
+//   - "file" is the path of the image shown by image items
+//   - "layout" is the time.Format layout of clock items, defaulting to "15:04"
+//   - "widget" is the key of the widget shown by widget items in the map of widgets
+//   - "duration" is the duration of the item, as a Go duration string such as "30s"
+//   - "repeat" is the number of times the item is played in succession
+//   - "window" optionally restricts the times at which the item is shown, with members "from" and "to",
+//     as times of day such as "08:30", and "days", as an array of days such as "mon"
+func Load(r io.Reader, dir string, widgets map[string]widget.Widget) (*Playlist, error) {
+	var jp jsonPlaylist
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&jp); err != nil {
+		return nil, fmt.Errorf("playlist: %w", err)
+	}
+
+	result := &Playlist{Items: make([]Item, 0, len(jp.Items))}
+	for i, ji := range jp.Items {
+		item, err := ji.item(dir, widgets)
+		if err != nil {
+			return nil, fmt.Errorf("playlist: items[%d]: %w", i, err)
+		}
+		result.Items = append(result.Items, item)
+	}
+	return result, nil
+}
+
+func (ji *jsonItem) item(dir string, widgets map[string]widget.Widget) (Item, error) {
+	item := Item{
+		Name:   ji.Name,
+		Repeat: ji.Repeat,
+	}
+
+	if ji.Duration != "" {
+		d, err := time.ParseDuration(ji.Duration)
+		if err != nil || d < 0 {
+			return item, fmt.Errorf("duration: invalid duration %q", ji.Duration)
+		}
+		item.Duration = d
+	}
+	if ji.Repeat < 0 {
+		return item, fmt.Errorf("repeat: must not be negative")
+	}
+
+	align, ok := alignments[ji.Align]
+	if !ok {
+		return item, fmt.Errorf("align: unrecognised alignment %q", ji.Align)
+	}
+
+	switch ji.Type {
+	case "text":
+		item.Content = &Text{Text: ji.Text, Align: align}
+	case "scroll":
+		item.Content = &Scroll{Text: ji.Text, Speed: ji.Speed}
+	case "image":
+		path := ji.File
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return item, fmt.Errorf("file: %w", err)
+		}
+		defer f.Close()
+		img, err := bitmap.Decode(f)
+		if err != nil {
+			return item, fmt.Errorf("file: %w", err)
+		}
+		item.Content = &Image{Image: img}
+	case "clock":
+		layout := ji.Layout
+		if layout == "" {
+			layout = "15:04"
+		}
+		item.Content = &Clock{Layout: layout, Align: align}
+	case "widget":
+		w, ok := widgets[ji.Widget]
+		if !ok || w == nil {
+			return item, fmt.Errorf("widget: no widget named %q", ji.Widget)
+		}
+		item.Content = &Widget{Widget: w}
+	default:
+		return item, fmt.Errorf("type: unrecognised type %q", ji.Type)
+	}
+
+	if ji.Window != nil {
+		w, err := ji.Window.window()
+		if err != nil {
+			return item, fmt.Errorf("window.%w", err)
+		}
+		item.Window = w
+	}
+	return item, nil
+}
+
+func (jw *jsonWindow) window() (*Window, error) {
+	w := &Window{}
+	for _, t := range []struct {
+		name  string
+		value string
+		dest  *time.Duration
+	}{{"from", jw.From, &w.From}, {"to", jw.To, &w.To}} {
+		if t.value == "" {
+			continue
+		}
+		tod, err := time.Parse("15:04", t.value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid time of day %q", t.name, t.value)
+		}
+		*t.dest = time.Duration(tod.Hour())*time.Hour + time.Duration(tod.Minute())*time.Minute
+	}
+
+	for i, d := range jw.Days {
+		day, ok := weekdays[strings.ToLower(d)]
+		if !ok {
+			return nil, fmt.Errorf("days[%d]: unrecognised day %q", i, d)
+		}
+		w.Days = append(w.Days, day)
+	}
+	return w, nil
+}
@@ -0,0 +1,318 @@
+package playlist
+
+import (
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/realency/arke/pkg/clock"
+	"github.com/realency/arke/pkg/display"
+	"github.com/realency/arke/pkg/viewport"
+	"github.com/realency/arke/pkg/widget"
+)
+
+// IdleInterval is the interval at which an idle scheduler, with no item eligible to be shown, checks again for eligible items.
+var IdleInterval = time.Second
+
+// A run is the playing of an item, which may be suspended by an interrupt and resumed later.
+type run struct {
+	item      Item
+	priority  int
+	remaining time.Duration
+	repeats   int
+}
+
+func newRun(item Item, priority int) *run {
+	repeats := item.Repeat
+	if repeats < 1 {
+		repeats = 1
+	}
+	return &run{
+		item:     item,
+		priority: priority,
+		repeats:  repeats,
+	}
+}
+
+// Scheduler plays a playlist on a viewport in a continuous loop.
+//
+// Items are played in order, skipping any that are outside their window.  When no item is eligible,
+// the display is blanked.  Priority items may be played by Interrupt, pre-empting the item currently
+// playing, which resumes for the rest of its duration once the interrupt has finished.
+type Scheduler struct {
+	mutex     sync.Mutex
+	viewPort  viewport.ViewPort
+	clock     clock.Clock
+	playlist  *Playlist
+	index     int
+	pending   []*run
+	preempted []*run
+	wake      chan struct{}
+	stop      chan struct{}
+	done      chan struct{}
+	started   bool
+}
+
+// NewScheduler returns a new instance of Scheduler, which plays content on the given viewport.
+// The scheduler does not play anything until Start is called.  Durations and windows are timed by the system clock,
+// unless another is selected by an option.
+func NewScheduler(vp viewport.ViewPort, opts ...clock.Option) *Scheduler {
+	return &Scheduler{
+		viewPort: vp,
+		clock:    clock.Of(opts),
+		playlist: &Playlist{},
+		wake:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// SetPlaylist replaces the playlist.  The item currently playing is allowed to finish, after which the new
+// playlist is played from the beginning.
+func (s *Scheduler) SetPlaylist(p *Playlist) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.playlist = p
+	s.index = 0
+	s.signal()
+}
+
+// Interrupt plays an item as soon as possible, pre-empting any item of lower priority.
+// Playlist items have priority zero, so the priority of an interrupt must be at least one.
+// Interrupts of equal priority are played in the order they are made.
+//
+// Panics if the priority is less than one.
+func (s *Scheduler) Interrupt(item Item, priority int) {
+	if priority < 1 {
+		panic("Interrupt priority must be at least 1")
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.pending = append(s.pending, newRun(item, priority))
+	s.signal()
+}
+
+// Start begins playing the playlist in the background.
+func (s *Scheduler) Start() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+	go s.run()
+}
+
+// Stop stops playing, and waits for the item currently playing to stop.
+func (s *Scheduler) Stop() {
+	s.mutex.Lock()
+	select {
+	case <-s.stop:
+	default:
+		close(s.stop)
+	}
+	started := s.started
+	s.mutex.Unlock()
+
+	if started {
+		<-s.done
+	}
+}
+
+// WatchFile loads a playlist from a JSON file, showing the given widgets as LoadFile does, and reloads it whenever the
+// file changes, until the scheduler is stopped.
+// The file is checked for changes at the given interval.  Returns an error if the playlist cannot be loaded initially;
+// errors on reloading are logged, and the previous playlist continues to play.
+func (s *Scheduler) WatchFile(path string, interval time.Duration, widgets map[string]widget.Widget) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	p, err := LoadFile(path, widgets)
+	if err != nil {
+		return err
+	}
+	s.SetPlaylist(p)
+
+	go func() {
+		modTime, size := info.ModTime(), info.Size()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-s.clock.After(interval):
+			}
+
+			info, err := os.Stat(path)
+			if err != nil || (info.ModTime().Equal(modTime) && info.Size() == size) {
+				continue
+			}
+			modTime, size = info.ModTime(), info.Size()
+
+			p, err := LoadFile(path, widgets)
+			if err != nil {
+				log.Println("WARNING Playlist not reloaded:", err)
+				continue
+			}
+			s.SetPlaylist(p)
+		}
+	}()
+	return nil
+}
+
+// Must be called with the mutex held.
+func (s *Scheduler) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Scheduler) run() {
+	defer close(s.done)
+	for {
+		r := s.pick(s.clock.Now())
+		if r == nil {
+			if !s.idle() {
+				return
+			}
+			continue
+		}
+		if !s.play(r) {
+			return
+		}
+	}
+}
+
+// Chooses the next run to play: the highest priority interrupt, if it outranks the most recently pre-empted run,
+// otherwise the most recently pre-empted run, otherwise the next eligible item in the playlist.
+func (s *Scheduler) pick(now time.Time) *run {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	best := -1
+	for i, p := range s.pending {
+		if best < 0 || p.priority > s.pending[best].priority {
+			best = i
+		}
+	}
+
+	var top *run
+	if n := len(s.preempted); n > 0 {
+		top = s.preempted[n-1]
+	}
+
+	if best >= 0 && (top == nil || s.pending[best].priority > top.priority) {
+		r := s.pending[best]
+		s.pending = append(s.pending[:best], s.pending[best+1:]...)
+		return r
+	}
+	if top != nil {
+		s.preempted = s.preempted[:len(s.preempted)-1]
+		return top
+	}
+
+	items := s.playlist.Items
+	for n := 0; n < len(items); n++ {
+		i := (s.index + n) % len(items)
+		if items[i].eligible(now) {
+			s.index = i + 1
+			return newRun(items[i], 0)
+		}
+	}
+	return nil
+}
+
+// Reports whether a pending interrupt outranks a run.
+func (s *Scheduler) outranked(r *run) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, p := range s.pending {
+		if p.priority > r.priority {
+			return true
+		}
+	}
+	return false
+}
+
+// Blanks the display until woken, or until it is time to check for eligible items again.
+// Returns false if the scheduler has been stopped.
+func (s *Scheduler) idle() bool {
+	h, w := s.viewPort.Size()
+	s.viewPort.Attach(display.NewCanvas(h, w), 0, 0)
+
+	select {
+	case <-s.stop:
+		return false
+	case <-s.wake:
+	case <-s.clock.After(IdleInterval):
+	}
+	return true
+}
+
+// Plays a run until it finishes, or until it is pre-empted, in which case it is suspended for resumption later.
+// Returns false if the scheduler has been stopped.
+func (s *Scheduler) play(r *run) bool {
+	for r.repeats > 0 {
+		d := r.remaining
+		if d == 0 {
+			d = r.item.Duration
+		}
+		if _, ok := r.item.Content.(finite); d == 0 && !ok {
+			d = DefaultDuration
+		}
+
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			r.item.Content.Play(s.viewPort, stop)
+		}()
+
+		var timeout <-chan time.Time
+		if d > 0 {
+			timeout = s.clock.After(d)
+		}
+		start := s.clock.Now()
+
+		finished := false
+		for !finished {
+			select {
+			case <-done:
+				finished = true
+			case <-timeout:
+				close(stop)
+				<-done
+				finished = true
+			case <-s.stop:
+				close(stop)
+				<-done
+				return false
+			case <-s.wake:
+				if !s.outranked(r) {
+					continue
+				}
+				close(stop)
+				<-done
+				if d > 0 {
+					r.remaining = d - s.clock.Now().Sub(start)
+					if r.remaining <= 0 {
+						r.remaining = 0
+						r.repeats--
+					}
+				}
+				if r.repeats > 0 {
+					s.mutex.Lock()
+					s.preempted = append(s.preempted, r)
+					s.mutex.Unlock()
+				}
+				return true
+			}
+		}
+
+		r.remaining = 0
+		r.repeats--
+	}
+	return true
+}
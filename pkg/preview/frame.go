@@ -0,0 +1,64 @@
+package preview
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/realency/arke/pkg/bits"
+	"github.com/realency/arke/pkg/viewport"
+)
+
+type overlay struct {
+	Label  string `json:"label"`
+	Row    int    `json:"row"`
+	Col    int    `json:"col"`
+	Height int    `json:"height"`
+	Width  int    `json:"width"`
+}
+
+// frame is the representation of the canvas sent to the browser.
+// Each row of pixels is encoded as a hexadecimal string, with the leftmost pixel in the most significant bit of the first byte.
+type frame struct {
+	Height    int       `json:"height"`
+	Width     int       `json:"width"`
+	Rows      []string  `json:"rows"`
+	ViewPorts []overlay `json:"viewports"`
+}
+
+type labelledViewPort struct {
+	label    string
+	viewPort viewport.ViewPort
+}
+
+func encodeFrame(m *bits.Matrix, viewPorts []labelledViewPort) []byte {
+	h, w := m.Size()
+	f := frame{
+		Height:    h,
+		Width:     w,
+		Rows:      make([]string, h),
+		ViewPorts: make([]overlay, 0, len(viewPorts)),
+	}
+
+	row := make([]byte, (w+7)/8)
+	for i := 0; i < h; i++ {
+		c := bits.NewCursor(m, i, 0)
+		for j := range row {
+			b, n := c.ReadRightByte()
+			row[j] = b << (8 - n)
+		}
+		f.Rows[i] = hex.EncodeToString(row)
+	}
+
+	for _, v := range viewPorts {
+		if v.viewPort.Canvas() == nil {
+			continue
+		}
+		o := overlay{Label: v.label}
+		o.Row, o.Col = v.viewPort.Offset()
+		o.Height, o.Width = v.viewPort.Size()
+		f.ViewPorts = append(f.ViewPorts, o)
+	}
+
+	result, _ := json.Marshal(f)
+	return result
+}
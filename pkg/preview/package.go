@@ -0,0 +1,6 @@
+// Package preview provides a local web server that shows a live, LED-style simulation of a display.Canvas in a browser.
+//
+// The server observes a canvas and streams each frame to the browser using Server-Sent Events, along with the
+// positions of any viewports attached to the canvas, which are drawn as overlays.  It is intended to allow
+// content to be designed and previewed without any display hardware.
+package preview
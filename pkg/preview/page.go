@@ -0,0 +1,69 @@
+package preview
+
+import "html/template"
+
+type pageSettings struct {
+	Pitch int
+	Glow  float64
+	Color string
+}
+
+var page = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>arke preview</title>
+<style>
+body { background: #111; color: #888; font-family: sans-serif; margin: 16px; }
+canvas { background: #000; }
+</style>
+</head>
+<body>
+<canvas id="display"></canvas>
+<p id="status">Connecting...</p>
+<script>
+const pitch = {{.Pitch}};
+const glow = {{.Glow}};
+const color = {{.Color}};
+const display = document.getElementById("display");
+const status = document.getElementById("status");
+const ctx = display.getContext("2d");
+
+function draw(f) {
+	display.width = f.width * pitch;
+	display.height = f.height * pitch;
+	ctx.fillStyle = "#000";
+	ctx.fillRect(0, 0, display.width, display.height);
+
+	for (let row = 0; row < f.height; row++) {
+		const hex = f.rows[row];
+		for (let col = 0; col < f.width; col++) {
+			const b = parseInt(hex.substr((col >> 3) * 2, 2), 16);
+			const on = (b & (0x80 >> (col & 7))) !== 0;
+			ctx.beginPath();
+			ctx.arc((col + 0.5) * pitch, (row + 0.5) * pitch, pitch * 0.4, 0, 2 * Math.PI);
+			ctx.shadowBlur = on ? glow * pitch : 0;
+			ctx.shadowColor = color;
+			ctx.fillStyle = on ? color : "#1a1a1a";
+			ctx.fill();
+		}
+	}
+
+	ctx.shadowBlur = 0;
+	ctx.strokeStyle = "#0af";
+	ctx.fillStyle = "#0af";
+	ctx.font = "12px sans-serif";
+	for (const v of f.viewports) {
+		ctx.strokeRect(v.col * pitch + 0.5, v.row * pitch + 0.5, v.width * pitch - 1, v.height * pitch - 1);
+		ctx.fillText(v.label, v.col * pitch + 3, v.row * pitch + 13);
+	}
+	status.textContent = f.width + " x " + f.height;
+}
+
+const events = new EventSource("events");
+events.onmessage = (e) => draw(JSON.parse(e.data));
+events.onerror = () => { status.textContent = "Disconnected"; };
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,215 @@
+package preview
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/realency/arke/pkg/bits"
+	"github.com/realency/arke/pkg/display"
+	"github.com/realency/arke/pkg/viewport"
+)
+
+// PollInterval is the interval at which the server checks attached viewports for changes in position.
+var PollInterval = 100 * time.Millisecond
+
+// Server is an http.Handler that serves a live preview of a canvas.
+//
+// The handler serves the preview page at its root, a stream of frames at "events", and a single
+// snapshot of the current frame at "frame".
+type Server struct {
+	canvas    *display.Canvas
+	mutex     sync.Mutex
+	settings  pageSettings
+	viewPorts []labelledViewPort
+	clients   map[chan []byte]struct{}
+	latest    []byte
+	id        uint64
+	updates   chan *bits.Matrix
+	stop      chan struct{}
+	mux       *http.ServeMux
+}
+
+// NewServer returns a new instance of Server, observing the given canvas.
+// The server observes the canvas until Close is called.
+func NewServer(canvas *display.Canvas) *Server {
+	s := &Server{
+		canvas: canvas,
+		settings: pageSettings{
+			Pitch: 12,
+			Glow:  0.5,
+			Color: "#ff3020",
+		},
+		clients: make(map[chan []byte]struct{}),
+		updates: make(chan *bits.Matrix, 20),
+		stop:    make(chan struct{}),
+		mux:     http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("/", s.servePage)
+	s.mux.HandleFunc("/events", s.serveEvents)
+	s.mux.HandleFunc("/frame", s.serveFrame)
+
+	var m *bits.Matrix
+	s.id, m = canvas.AddObserver(s.updates)
+	s.latest = encodeFrame(m, nil)
+	go s.run()
+	return s
+}
+
+// SetPitch sets the distance between the centres of adjacent pixels on the page, in browser pixels.
+func (s *Server) SetPitch(pitch int) {
+	if pitch < 1 {
+		pitch = 1
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.settings.Pitch = pitch
+}
+
+// SetGlow sets the radius of the glow around lit pixels, as a proportion of the pixel pitch.
+func (s *Server) SetGlow(glow float64) {
+	if glow < 0 {
+		glow = 0
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.settings.Glow = glow
+}
+
+// SetColor sets the colour of lit pixels, as a CSS colour value.
+func (s *Server) SetColor(color string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.settings.Color = color
+}
+
+// AddViewPort registers a viewport whose position is shown as a labelled overlay on the preview.
+// The overlay is shown only while the viewport is attached to a canvas.
+func (s *Server) AddViewPort(label string, vp viewport.ViewPort) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.viewPorts = append(s.viewPorts, labelledViewPort{label, vp})
+}
+
+// Close stops the server observing the canvas, and ends any open event streams.
+func (s *Server) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	select {
+	case <-s.stop:
+		return
+	default:
+	}
+	s.canvas.RemoveObserver(s.id)
+	close(s.stop)
+}
+
+// ListenAndServe listens on the given address and serves the preview until an error occurs.
+// If the address does not include a host, the server listens on localhost only.
+func (s *Server) ListenAndServe(addr string) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("preview: invalid address %q: %w", addr, err)
+	}
+	if host == "" {
+		host = "localhost"
+	}
+	return http.ListenAndServe(net.JoinHostPort(host, port), s)
+}
+
+// ServeHTTP serves the preview page, the stream of frames, and frame snapshots.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) run() {
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
+
+	var current *bits.Matrix
+	for {
+		select {
+		case <-s.stop:
+			return
+		case m := <-s.updates:
+			current = m
+		case <-ticker.C:
+			if current == nil {
+				current = s.canvas.Matrix()
+			}
+		}
+
+		s.mutex.Lock()
+		f := encodeFrame(current, s.viewPorts)
+		if string(f) != string(s.latest) {
+			s.latest = f
+			for c := range s.clients {
+				select {
+				case c <- f:
+				default:
+				}
+			}
+		}
+		s.mutex.Unlock()
+	}
+}
+
+func (s *Server) servePage(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	s.mutex.Lock()
+	settings := s.settings
+	s.mutex.Unlock()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	page.Execute(w, settings)
+}
+
+func (s *Server) serveFrame(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	f := s.latest
+	s.mutex.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(f)
+}
+
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	frames := make(chan []byte, 4)
+	s.mutex.Lock()
+	s.clients[frames] = struct{}{}
+	frames <- s.latest
+	s.mutex.Unlock()
+
+	defer func() {
+		s.mutex.Lock()
+		delete(s.clients, frames)
+		s.mutex.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.stop:
+			return
+		case f := <-frames:
+			fmt.Fprintf(w, "data: %s\n\n", f)
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package recording
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/realency/arke/pkg/bitmap"
+	"github.com/realency/arke/pkg/bits"
+)
+
+// The file format starts with a header of magic bytes and a version, followed by the frames as a stream of bit
+// matrices in the compact format of package bitmap, which records the height and width of the frames.
+// Each matrix is preceded by the time of the frame, in microseconds since the start of the recording, as an unsigned varint.
+var magic = []byte("ARKR")
+
+const version = 2
+
+// Limits on the size of frames, matching those of the compact format, so that every recording may be read back
+const (
+	maxDimension = 1 << 16
+	maxPixels    = 1 << 24
+)
+
+// ErrFormat is returned by a Decoder when its input is not a valid recording.
+var ErrFormat = errors.New("recording: invalid format")
+
+// Frame is a single frame of a recording.
+type Frame struct {
+	// Time is the time at which the frame was published, relative to the start of the recording
+	Time time.Duration
+
+	Matrix *bits.Matrix
+}
+
+// An Encoder writes frames to an output stream in the recording format.
+type Encoder struct {
+	w             io.Writer
+	height, width int
+	header        bool
+	frames        *bitmap.Encoder
+	time          time.Duration
+}
+
+// NewEncoder returns a new instance of Encoder, which writes frames of a given size to w.
+func NewEncoder(w io.Writer, height, width int) *Encoder {
+	return &Encoder{
+		w:      w,
+		height: height,
+		width:  width,
+		frames: bitmap.NewEncoder(w),
+	}
+}
+
+// WriteHeader writes the header of the recording, if it has not already been written.  Encode writes the header
+// before the first frame, so WriteHeader is needed only to write a recording with no frames.
+// Returns an error if the size given to NewEncoder is too large to be read back.
+func (e *Encoder) WriteHeader() error {
+	if e.header {
+		return nil
+	}
+	if e.height > maxDimension || e.width > maxDimension || e.height*e.width > maxPixels {
+		return fmt.Errorf("recording: frame size %dx%d is too large", e.height, e.width)
+	}
+
+	if _, err := e.w.Write(append(append([]byte(nil), magic...), version)); err != nil {
+		return fmt.Errorf("recording: writing header: %w", err)
+	}
+	if err := e.frames.WriteHeader(e.height, e.width); err != nil {
+		return fmt.Errorf("recording: writing header: %w", err)
+	}
+	e.header = true
+	return nil
+}
+
+// Encode writes a frame.  Frames must be encoded in time order.
+// Returns an error if the frame is not the size given to NewEncoder, or if it is earlier than the previous frame.
+func (e *Encoder) Encode(f Frame) error {
+	if h, w := f.Matrix.Size(); h != e.height || w != e.width {
+		return fmt.Errorf("recording: frame size %dx%d does not match recording size %dx%d", h, w, e.height, e.width)
+	}
+	if f.Time < e.time || f.Time < 0 {
+		return errors.New("recording: frames out of order")
+	}
+	if err := e.WriteHeader(); err != nil {
+		return err
+	}
+
+	// Times are recorded in whole microseconds, measured from the start of the recording, so that rounding errors
+	// do not accumulate from frame to frame
+	if _, err := e.w.Write(appendUvarint(nil, uint64(f.Time/time.Microsecond))); err != nil {
+		return fmt.Errorf("recording: writing frame: %w", err)
+	}
+	if err := e.frames.Encode(f.Matrix); err != nil {
+		return fmt.Errorf("recording: writing frame: %w", err)
+	}
+	e.time = f.Time
+	return nil
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	return append(buf, b[:n]...)
+}
+
+// A Decoder reads frames from an input stream in the recording format.
+type Decoder struct {
+	r             *bufio.Reader
+	header        bool
+	height, width int
+	frames        *bitmap.Decoder
+	time          time.Duration
+}
+
+// NewDecoder returns a new instance of Decoder, which reads frames from r.
+func NewDecoder(r io.Reader) *Decoder {
+	br := bufio.NewReader(r)
+	return &Decoder{
+		r:      br,
+		frames: bitmap.NewDecoder(br),
+	}
+}
+
+// Size returns the size of the frames in the recording, reading the header of the recording if necessary.
+func (d *Decoder) Size() (height, width int, err error) {
+	if err := d.readHeader(); err != nil {
+		return 0, 0, err
+	}
+	return d.height, d.width, nil
+}
+
+func (d *Decoder) readHeader() error {
+	if d.header {
+		return nil
+	}
+
+	head := make([]byte, len(magic)+1)
+	if _, err := io.ReadFull(d.r, head); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return ErrFormat
+		}
+		return fmt.Errorf("recording: reading header: %w", err)
+	}
+	if string(head[:len(magic)]) != string(magic) || head[len(magic)] != version {
+		return ErrFormat
+	}
+
+	h, w, err := d.frames.Size()
+	if err != nil {
+		return d.fail(err)
+	}
+
+	d.height, d.width = h, w
+	d.header = true
+	return nil
+}
+
+// Returns the error to report for a failure to read frames, treating invalid compact data as an invalid recording.
+func (d *Decoder) fail(err error) error {
+	if errors.Is(err, bitmap.ErrCompact) || err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrFormat
+	}
+	return fmt.Errorf("recording: reading frame: %w", err)
+}
+
+// Decode reads the next frame.  Returns io.EOF when there are no more frames.
+func (d *Decoder) Decode() (Frame, error) {
+	if err := d.readHeader(); err != nil {
+		return Frame{}, err
+	}
+	if _, err := d.r.Peek(1); err == io.EOF {
+		return Frame{}, io.EOF
+	}
+
+	micros, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return Frame{}, d.fail(err)
+	}
+	t := time.Duration(micros) * time.Microsecond
+	if micros > uint64(math.MaxInt64/time.Microsecond) || t < d.time {
+		return Frame{}, ErrFormat
+	}
+
+	m, err := d.frames.Decode()
+	if err != nil {
+		return Frame{}, d.fail(err)
+	}
+
+	d.time = t
+	return Frame{Time: d.time, Matrix: m}, nil
+}
@@ -0,0 +1,72 @@
+package recording
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"time"
+)
+
+// Colours in which pixels are drawn by WriteGIF, resembling a red LED matrix.
+var (
+	GIFOn  color.Color = color.RGBA{0xff, 0x30, 0x00, 0xff}
+	GIFOff color.Color = color.RGBA{0x24, 0x0a, 0x00, 0xff}
+)
+
+// GIFLastFrame is the time for which the last frame of a recording is shown by a GIF, before it loops.
+var GIFLastFrame = time.Second
+
+// WriteGIF writes a recording as a looping animated GIF, in which each pixel is drawn as a square of the given scale.
+//
+// GIF frame delays are in hundredths of a second, so frames that would be shown for less than that are dropped.
+// Panics if scale is less than 1.
+func WriteGIF(w io.Writer, rec *Recording, scale int) error {
+	if scale < 1 {
+		panic("Scale must be at least 1")
+	}
+	if len(rec.Frames) == 0 {
+		return errors.New("recording: no frames to export")
+	}
+
+	palette := color.Palette{GIFOff, GIFOn}
+	result := &gif.GIF{}
+	for i, f := range rec.Frames {
+		next := f.Time + GIFLastFrame
+		if i+1 < len(rec.Frames) {
+			next = rec.Frames[i+1].Time
+		}
+		delay := centiseconds(next) - centiseconds(f.Time)
+		if delay == 0 {
+			continue
+		}
+
+		img := image.NewPaletted(image.Rect(0, 0, rec.Width*scale, rec.Height*scale), palette)
+		for row := 0; row < rec.Height; row++ {
+			for col := 0; col < rec.Width; col++ {
+				if !f.Matrix.Get(row, col) {
+					continue
+				}
+				for y := row * scale; y < (row+1)*scale; y++ {
+					for x := col * scale; x < (col+1)*scale; x++ {
+						img.SetColorIndex(x, y, 1)
+					}
+				}
+			}
+		}
+		result.Image = append(result.Image, img)
+		result.Delay = append(result.Delay, delay)
+	}
+
+	if err := gif.EncodeAll(w, result); err != nil {
+		return fmt.Errorf("recording: writing GIF: %w", err)
+	}
+	return nil
+}
+
+// Frame times are rounded down to whole hundredths of a second, so that rounding errors don't accumulate over a recording.
+func centiseconds(d time.Duration) int {
+	return int(d / (10 * time.Millisecond))
+}
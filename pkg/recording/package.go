@@ -0,0 +1,6 @@
+// Package recording records the frames published by a display.Canvas, with their timestamps, and replays them.
+//
+// Recordings are stored with their frames in the compact format of package bitmap, in which each frame may be
+// encoded as its difference from the previous frame, so that a long recording of a mostly static sign stays small.
+// A recording may also be exported as an animated GIF, for example to attach to a bug report.
+package recording
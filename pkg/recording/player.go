@@ -0,0 +1,73 @@
+package recording
+
+import (
+	"sync"
+	"time"
+
+	"github.com/realency/arke/pkg/clock"
+	"github.com/realency/arke/pkg/display"
+)
+
+// A Player is a recording being replayed onto a canvas.
+//
+// Players are created by Play, and run in the background until they either complete or are cancelled.
+type Player struct {
+	cancel    chan struct{}
+	done      chan struct{}
+	once      sync.Once
+	completed bool
+}
+
+// Play begins replaying a recording onto a canvas, with the top-left corner of each frame at the top-left corner
+// of the canvas.  Frames are clipped to the extent of the canvas.
+//
+// Speed scales the rate of replay: 1 replays in real time, and 2 replays at twice the speed.  Panics if speed is not positive.
+// Frames are timed by the system clock, unless another is selected by an option.
+func Play(canvas *display.Canvas, rec *Recording, speed float64, opts ...clock.Option) *Player {
+	if speed <= 0 {
+		panic("Speed must be positive")
+	}
+
+	p := &Player{
+		cancel: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go p.run(canvas, rec, speed, clock.Of(opts))
+	return p
+}
+
+func (p *Player) run(canvas *display.Canvas, rec *Recording, speed float64, clk clock.Clock) {
+	defer close(p.done)
+
+	start := clk.Now()
+	for _, f := range rec.Frames {
+		due := start.Add(time.Duration(float64(f.Time) / speed))
+		select {
+		case <-p.cancel:
+			return
+		case <-clk.After(due.Sub(clk.Now())):
+		}
+		canvas.Write(f.Matrix, 0, 0)
+	}
+	p.completed = true
+}
+
+// Cancel stops replaying, leaving the most recently replayed frame on the canvas.
+// Cancelling a player that has already finished has no effect.
+func (p *Player) Cancel() {
+	p.once.Do(func() {
+		close(p.cancel)
+	})
+}
+
+// Done returns a channel that is closed when replay finishes, either by completing or by being cancelled.
+func (p *Player) Done() <-chan struct{} {
+	return p.done
+}
+
+// Wait blocks until replay finishes.
+// Returns true if the recording was replayed to completion, or false if it was cancelled.
+func (p *Player) Wait() bool {
+	<-p.done
+	return p.completed
+}
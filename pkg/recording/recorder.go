@@ -0,0 +1,181 @@
+package recording
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/realency/arke/pkg/bits"
+	"github.com/realency/arke/pkg/clock"
+	"github.com/realency/arke/pkg/display"
+)
+
+// Recording is a complete recording, held in memory.
+type Recording struct {
+	Height, Width int
+	Frames        []Frame
+}
+
+// Load reads a complete recording.
+func Load(r io.Reader) (*Recording, error) {
+	d := NewDecoder(r)
+	h, w, err := d.Size()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Recording{Height: h, Width: w}
+	for {
+		f, err := d.Decode()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		result.Frames = append(result.Frames, f)
+	}
+}
+
+// Save writes a complete recording.  A recording with no frames is written as just a header, which Load reads back.
+func (rec *Recording) Save(w io.Writer) error {
+	e := NewEncoder(w, rec.Height, rec.Width)
+	if err := e.WriteHeader(); err != nil {
+		return err
+	}
+	for _, f := range rec.Frames {
+		if err := e.Encode(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Duration returns the time of the last frame of the recording.
+func (rec *Recording) Duration() time.Duration {
+	if len(rec.Frames) == 0 {
+		return 0
+	}
+	return rec.Frames[len(rec.Frames)-1].Time
+}
+
+// Number of frames a Recorder holds while its output stream is busy, beyond which further frames are dropped.
+const backlog = 1024
+
+// A Recorder records every frame published by a canvas, writing each to an output stream as it is published.
+//
+// Frames are timed as they are received from the canvas, and written in the background, so that a slow output
+// stream delays the writing of frames but not their times.  If the output stream falls too far behind, frames are
+// dropped, and counted by Dropped.
+type Recorder struct {
+	mutex   sync.Mutex
+	once    sync.Once
+	clock   clock.Clock
+	canvas  *display.Canvas
+	id      uint64
+	encoder *Encoder
+	start   time.Time
+	err     error
+	dropped int64 // Accessed atomically, so that counting never waits for the writer
+	updates chan *bits.Matrix
+	frames  chan Frame
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// Record starts recording a canvas to w.  The first frame of the recording is the content of the canvas
+// when recording starts, at time zero.  Recording continues until Stop is called.
+// Frames are timed by the system clock, unless another is selected by an option.
+func Record(canvas *display.Canvas, w io.Writer, opts ...clock.Option) *Recorder {
+	h, width := canvas.Size()
+	r := &Recorder{
+		clock:   clock.Of(opts),
+		canvas:  canvas,
+		encoder: NewEncoder(w, h, width),
+		updates: make(chan *bits.Matrix, 64),
+		frames:  make(chan Frame, backlog),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	var m *bits.Matrix
+	r.id, m = canvas.AddObserver(r.updates)
+	r.start = r.clock.Now()
+	r.encode(Frame{Matrix: m})
+	go r.receive()
+	go r.write()
+	return r
+}
+
+// Times each frame as it is published, and passes it to the writer, unless the writer is too far behind.
+func (r *Recorder) receive() {
+	defer close(r.frames)
+	for {
+		select {
+		case m := <-r.updates:
+			r.queue(m)
+		case <-r.stop:
+			// Frames already published are recorded before stopping
+			for {
+				select {
+				case m := <-r.updates:
+					r.queue(m)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (r *Recorder) queue(m *bits.Matrix) {
+	select {
+	case r.frames <- Frame{Time: r.clock.Now().Sub(r.start), Matrix: m}:
+	default:
+		atomic.AddInt64(&r.dropped, 1)
+	}
+}
+
+func (r *Recorder) write() {
+	defer close(r.done)
+	for f := range r.frames {
+		r.encode(f)
+	}
+}
+
+// Writes a frame, unless writing has already failed.  The mutex guards only the error, so that Err does not wait
+// for a slow output stream.
+func (r *Recorder) encode(f Frame) {
+	if r.Err() != nil {
+		return
+	}
+	if err := r.encoder.Encode(f); err != nil {
+		r.mutex.Lock()
+		r.err = err
+		r.mutex.Unlock()
+	}
+}
+
+// Stop stops recording, once every frame already published has been written.
+// Returns the first error in writing the recording, if any.
+func (r *Recorder) Stop() error {
+	r.once.Do(func() {
+		r.canvas.RemoveObserver(r.id)
+		close(r.stop)
+	})
+	<-r.done
+	return r.Err()
+}
+
+// Err returns the first error in writing the recording, or nil if there has been none.
+func (r *Recorder) Err() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.err
+}
+
+// Dropped returns the number of frames dropped so far because the output stream could not keep up.
+func (r *Recorder) Dropped() int {
+	return int(atomic.LoadInt64(&r.dropped))
+}
@@ -0,0 +1,64 @@
+package terminal
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+// Builder is a builder type for creating a ViewPort in a fluent programming style.
+type Builder struct {
+	out           io.Writer
+	height, width int
+	style         Style
+	on, off       Color
+}
+
+// ToStdout creates a new Builder for a ViewPort that draws to standard output.
+func ToStdout() *Builder {
+	return ToWriter(os.Stdout)
+}
+
+// ToWriter creates a new Builder for a ViewPort that draws to the given writer.
+func ToWriter(out io.Writer) *Builder {
+	return &Builder{
+		out:   out,
+		style: HalfBlocks,
+		on:    NoColor,
+		off:   NoColor,
+	}
+}
+
+// WithSize specifies the size of the ViewPort in pixels, and returns the Builder.
+func (b *Builder) WithSize(height, width int) *Builder {
+	b.height = height
+	b.width = width
+	return b
+}
+
+// WithStyle specifies the characters used to draw pixels, and returns the Builder.
+func (b *Builder) WithStyle(style Style) *Builder {
+	b.style = style
+	return b
+}
+
+// WithColors specifies the ANSI colours of lit and unlit pixels, and returns the Builder.
+func (b *Builder) WithColors(on, off Color) *Builder {
+	b.on = on
+	b.off = off
+	return b
+}
+
+// Build builds the ViewPort, ready to be attached to a canvas, or returns an error if the configuration is incomplete.
+func (b *Builder) Build() (*ViewPort, error) {
+	if b.out == nil {
+		return nil, errors.New("terminal: no writer specified")
+	}
+	if b.height <= 0 || b.width <= 0 {
+		return nil, errors.New("terminal: viewport size must be specified")
+	}
+	if b.style < Blocks || b.style > Braille {
+		return nil, errors.New("terminal: unrecognised style")
+	}
+	return newViewPort(b.out, b.height, b.width, b.style, b.on, b.off), nil
+}
@@ -0,0 +1,6 @@
+// Package terminal provides an implementation of viewport.ViewPort that draws to a text terminal, or any io.Writer.
+//
+// A terminal ViewPort allows display applications to be developed and demonstrated without hardware, using
+// the same Attach and Locate logic that drives a physical display.  Pixels are drawn using Unicode block or
+// braille characters, optionally coloured using ANSI escape sequences, and each frame is redrawn in place.
+package terminal
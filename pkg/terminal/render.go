@@ -0,0 +1,175 @@
+package terminal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/realency/arke/pkg/bits"
+)
+
+// Style determines the characters used to draw pixels.
+type Style int
+
+// Constant values for drawing styles.
+const (
+	// Each pixel is drawn as two full-block characters, so that pixels appear roughly square
+	Blocks Style = 0
+
+	// Each character cell draws two pixels, one above the other, using half-block characters
+	HalfBlocks Style = 1
+
+	// Each character cell draws a block of eight pixels, four high and two wide, using braille characters
+	Braille Style = 2
+)
+
+// Color is an ANSI colour, as an index into the 256-colour palette supported by most terminals.
+type Color int
+
+// Constant values for commonly used colours.
+const (
+	// No colour escapes are written; pixels are drawn in the terminal's default colours
+	NoColor Color = -1
+
+	Black   Color = 0
+	Red     Color = 9
+	Green   Color = 10
+	Yellow  Color = 11
+	Blue    Color = 12
+	White   Color = 15
+	Amber   Color = 214
+	DarkRed Color = 52
+	Grey    Color = 236
+)
+
+func (c Color) foreground() string {
+	if c == NoColor {
+		return ""
+	}
+	return fmt.Sprintf("\x1b[38;5;%dm", c)
+}
+
+func (c Color) background() string {
+	if c == NoColor {
+		return ""
+	}
+	return fmt.Sprintf("\x1b[48;5;%dm", c)
+}
+
+// Braille dot values, indexed by row and column within a character cell.
+var brailleDots = [4][2]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// A renderer draws a bit matrix as lines of text.
+type renderer struct {
+	style   Style
+	on, off Color
+}
+
+// Returns the number of lines of text needed to draw a matrix of the given height.
+func (r renderer) lines(height int) int {
+	switch r.style {
+	case HalfBlocks:
+		return (height + 1) / 2
+	case Braille:
+		return (height + 3) / 4
+	}
+	return height
+}
+
+func (r renderer) render(m *bits.Matrix) string {
+	h, w := m.Size()
+	get := func(row, col int) bool {
+		return row < h && col < w && m.Get(row, col)
+	}
+
+	var sb strings.Builder
+	for line := 0; line < r.lines(h); line++ {
+		switch r.style {
+		case Blocks:
+			r.blocksLine(&sb, get, line, w)
+		case HalfBlocks:
+			r.halfBlocksLine(&sb, get, line, w)
+		case Braille:
+			r.brailleLine(&sb, get, line, w)
+		}
+		if r.on != NoColor || r.off != NoColor {
+			sb.WriteString("\x1b[0m")
+		}
+		sb.WriteString("\x1b[K\n")
+	}
+	return sb.String()
+}
+
+func (r renderer) blocksLine(sb *strings.Builder, get func(row, col int) bool, line, width int) {
+	for col := 0; col < width; col++ {
+		if get(line, col) {
+			sb.WriteString(r.on.foreground())
+			sb.WriteString("██")
+		} else if r.off != NoColor {
+			sb.WriteString(r.off.foreground())
+			sb.WriteString("██")
+		} else {
+			sb.WriteString("  ")
+		}
+	}
+}
+
+func (r renderer) halfBlocksLine(sb *strings.Builder, get func(row, col int) bool, line, width int) {
+	if r.on == NoColor && r.off == NoColor {
+		for col := 0; col < width; col++ {
+			top, bottom := get(line*2, col), get(line*2+1, col)
+			switch {
+			case top && bottom:
+				sb.WriteRune('█')
+			case top:
+				sb.WriteRune('▀')
+			case bottom:
+				sb.WriteRune('▄')
+			default:
+				sb.WriteRune(' ')
+			}
+		}
+		return
+	}
+
+	// In colour, the upper half-block's foreground draws the top pixel, and its background draws the bottom pixel
+	for col := 0; col < width; col++ {
+		top, bottom := get(line*2, col), get(line*2+1, col)
+		sb.WriteString(r.color(top).foreground())
+		sb.WriteString(r.color(bottom).background())
+		sb.WriteRune('▀')
+	}
+}
+
+func (r renderer) brailleLine(sb *strings.Builder, get func(row, col int) bool, line, width int) {
+	sb.WriteString(r.on.foreground())
+	sb.WriteString(r.off.background())
+	for col := 0; col < width; col += 2 {
+		var ch rune = 0x2800
+		for i := 0; i < 4; i++ {
+			for j := 0; j < 2; j++ {
+				if get(line*4+i, col+j) {
+					ch |= brailleDots[i][j]
+				}
+			}
+		}
+		sb.WriteRune(ch)
+	}
+}
+
+func (r renderer) color(on bool) Color {
+	if on {
+		if r.on == NoColor {
+			return White
+		}
+		return r.on
+	}
+	if r.off == NoColor {
+		return Black
+	}
+	return r.off
+}
@@ -0,0 +1,246 @@
+package terminal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/realency/arke/pkg/bits"
+	"github.com/realency/arke/pkg/display"
+	"github.com/realency/arke/pkg/viewport"
+)
+
+// ViewPort provides an implementation of interface viewport.ViewPort that draws to a text terminal.
+//
+// Each frame is drawn over the previous one by moving the cursor back up to the first line of the frame,
+// so nothing else should be written to the terminal while the ViewPort is attached.
+type ViewPort struct {
+	mutex         sync.Mutex
+	out           io.Writer
+	renderer      renderer
+	canvas        *display.Canvas
+	feed          *viewport.Feed
+	row, col      int
+	height, width int
+	drawn         int
+	asleep        bool
+	closed        bool
+	err           error
+}
+
+func newViewPort(out io.Writer, height, width int, style Style, on, off Color) *ViewPort {
+	return &ViewPort{
+		out:      out,
+		renderer: renderer{style, on, off},
+		row:      -1,
+		col:      -1,
+		height:   height,
+		width:    width,
+	}
+}
+
+// Draws a snapshot from a feed, unless the feed has since been replaced by a later attachment.
+func (vp *ViewPort) handleFeed(f *viewport.Feed, m *bits.Matrix) {
+	vp.mutex.Lock()
+	defer vp.mutex.Unlock()
+	if f == vp.feed {
+		vp.handleUpdate(m)
+	}
+}
+
+func (vp *ViewPort) setOffset(row, col int) {
+	h, w := vp.canvas.Size()
+	if row+vp.height > h {
+		row = h - vp.height
+	}
+	if row < 0 {
+		row = 0
+	}
+	if col+vp.width > w {
+		col = w - vp.width
+	}
+	if col < 0 {
+		col = 0
+	}
+	vp.row, vp.col = row, col
+}
+
+func (vp *ViewPort) handleUpdate(buff *bits.Matrix) {
+	if bh, bw := buff.Size(); bh == 0 || bw == 0 {
+		return
+	}
+
+	frame := bits.NewMatrix(vp.height, vp.width)
+	bits.Copy(buff, vp.row, vp.col, frame, 0, 0, vp.height, vp.width)
+	vp.draw(frame)
+}
+
+// Draws a frame, unless the ViewPort is asleep, in which case the display remains blank.
+func (vp *ViewPort) draw(frame *bits.Matrix) {
+	if vp.asleep {
+		return
+	}
+	vp.write(frame)
+}
+
+func (vp *ViewPort) write(frame *bits.Matrix) {
+	text := vp.renderer.render(frame)
+	if vp.drawn > 0 {
+		text = fmt.Sprintf("\x1b[%dA\r", vp.drawn) + text
+	}
+	if _, err := io.WriteString(vp.out, text); err != nil {
+		vp.err = err
+	}
+	vp.drawn = vp.renderer.lines(vp.height)
+}
+
+// Redraws the current content of the canvas, or a blank frame if the ViewPort is detached.
+func (vp *ViewPort) redraw() {
+	if vp.canvas == nil {
+		vp.draw(bits.NewMatrix(vp.height, vp.width))
+		return
+	}
+	vp.handleUpdate(vp.canvas.Matrix())
+}
+
+// Attach attaches the ViewPort to a canvas at a specific location, so that changes in region framed by the canvas are reflected in the display.
+func (vp *ViewPort) Attach(canvas *display.Canvas, row, col int) {
+	if canvas == nil {
+		vp.Detach()
+		return
+	}
+
+	vp.mutex.Lock()
+	defer vp.mutex.Unlock()
+
+	if vp.canvas == canvas {
+		return
+	}
+	if vp.canvas != nil {
+		vp.feed.Stop()
+	}
+
+	var b *bits.Matrix
+	vp.canvas = canvas
+	vp.feed, b = viewport.NewFeed(canvas, 20, vp.handleFeed)
+	vp.setOffset(row, col)
+	vp.handleUpdate(b)
+}
+
+// Detach detaches the ViewPort from the canvas it is currently attached to, and blanks the display.
+func (vp *ViewPort) Detach() {
+	vp.mutex.Lock()
+	defer vp.mutex.Unlock()
+
+	if vp.canvas == nil {
+		return
+	}
+	vp.feed.Stop()
+	vp.canvas = nil
+	vp.feed = nil
+	vp.row, vp.col = -1, -1
+	vp.draw(bits.NewMatrix(vp.height, vp.width))
+}
+
+// Locate repositions the ViewPort at a new position on the underlying canvas.
+func (vp *ViewPort) Locate(row, col int) {
+	vp.mutex.Lock()
+	defer vp.mutex.Unlock()
+
+	if vp.canvas == nil {
+		return
+	}
+	vp.setOffset(row, col)
+	vp.handleUpdate(vp.canvas.Matrix())
+}
+
+// Sleep blanks the display.  The ViewPort continues to track its canvas while asleep, and Wake redraws the current content.
+func (vp *ViewPort) Sleep() {
+	vp.mutex.Lock()
+	defer vp.mutex.Unlock()
+
+	if vp.asleep {
+		return
+	}
+	vp.write(bits.NewMatrix(vp.height, vp.width))
+	vp.asleep = true
+}
+
+// Wake restores the display after Sleep.
+func (vp *ViewPort) Wake() {
+	vp.mutex.Lock()
+	defer vp.mutex.Unlock()
+
+	if !vp.asleep {
+		return
+	}
+	vp.asleep = false
+	vp.redraw()
+}
+
+// Asleep reports whether the display is asleep.
+func (vp *ViewPort) Asleep() bool {
+	vp.mutex.Lock()
+	defer vp.mutex.Unlock()
+	return vp.asleep
+}
+
+// Close detaches the ViewPort, blanks the display and stops drawing updates.
+// The writer is not closed.  The ViewPort must not be used after it is closed.
+func (vp *ViewPort) Close() error {
+	vp.mutex.Lock()
+	defer vp.mutex.Unlock()
+
+	if vp.closed {
+		return nil
+	}
+	if vp.canvas != nil {
+		vp.feed.Stop()
+		vp.canvas = nil
+		vp.feed = nil
+	}
+	vp.row, vp.col = -1, -1
+	vp.draw(bits.NewMatrix(vp.height, vp.width))
+	vp.closed = true
+	return vp.err
+}
+
+// Flush blocks until every update to the canvas made before the call has been drawn, or until the context is done,
+// in which case it returns the error of the context.
+func (vp *ViewPort) Flush(ctx context.Context) error {
+	vp.mutex.Lock()
+	feed := vp.feed
+	vp.mutex.Unlock()
+
+	if feed == nil {
+		return nil
+	}
+	return feed.Flush(ctx)
+}
+
+// Err returns the most recent error in writing to the terminal, or nil if there has been none.
+func (vp *ViewPort) Err() error {
+	vp.mutex.Lock()
+	defer vp.mutex.Unlock()
+	return vp.err
+}
+
+// Offset returns the current location of the ViewPort - its offset into the canvas.
+func (vp *ViewPort) Offset() (row, col int) {
+	vp.mutex.Lock()
+	defer vp.mutex.Unlock()
+	return vp.row, vp.col
+}
+
+// Size returns the size of the ViewPort in pixels.
+func (vp *ViewPort) Size() (height, width int) {
+	return vp.height, vp.width
+}
+
+// Canvas returns the attached canvas, or nil if the ViewPort is not attached to any canvas.
+func (vp *ViewPort) Canvas() *display.Canvas {
+	vp.mutex.Lock()
+	defer vp.mutex.Unlock()
+	return vp.canvas
+}
@@ -0,0 +1,216 @@
+package transition
+
+import (
+	"math/rand"
+
+	"github.com/realency/arke/pkg/bits"
+)
+
+// Direction represents the direction of motion of an effect across the display.
+type Direction int
+
+// Constant values for the direction of motion of an effect.
+const (
+	// Content moves towards the top of the display
+	Up Direction = 0
+
+	// Content moves towards the bottom of the display
+	Down Direction = 1
+
+	// Content moves towards the left of the display
+	Left Direction = 2
+
+	// Content moves towards the right of the display
+	Right Direction = 3
+)
+
+// Effect computes a single intermediate frame of a transition.
+//
+// The frame is written to dest, which has the same size as from and to.  Progress is in the range 0..1,
+// inclusive, where 0 represents the starting frame and 1 represents the finishing frame.
+// Every pixel in dest should be written; dest is not guaranteed to hold any previous frame.
+type Effect func(dest, from, to *bits.Matrix, progress float64)
+
+// Cut switches from one frame to the next without any intermediate frames.
+func Cut() Effect {
+	return func(dest, from, to *bits.Matrix, progress float64) {
+		if progress < 1 {
+			place(from, dest, 0, 0)
+		} else {
+			place(to, dest, 0, 0)
+		}
+	}
+}
+
+// SlideIn moves the finishing frame in the given direction, over the top of the starting frame.
+// For example, SlideIn(Left) brings the finishing frame in from the right-hand edge of the display.
+func SlideIn(d Direction) Effect {
+	return func(dest, from, to *bits.Matrix, progress float64) {
+		h, w := dest.Size()
+		dr, dc := displacement(d, h, w, progress)
+		row, col := entryOrigin(d, h, w)
+		place(from, dest, 0, 0)
+		place(to, dest, row+dr, col+dc)
+	}
+}
+
+// SlideOut moves the starting frame in the given direction, uncovering the finishing frame beneath it.
+// For example, SlideOut(Up) moves the starting frame off the top edge of the display.
+func SlideOut(d Direction) Effect {
+	return func(dest, from, to *bits.Matrix, progress float64) {
+		h, w := dest.Size()
+		dr, dc := displacement(d, h, w, progress)
+		place(to, dest, 0, 0)
+		place(from, dest, dr, dc)
+	}
+}
+
+// Push moves both frames together in the given direction, so that the finishing frame pushes the starting frame off the display.
+func Push(d Direction) Effect {
+	return func(dest, from, to *bits.Matrix, progress float64) {
+		h, w := dest.Size()
+		dr, dc := displacement(d, h, w, progress)
+		row, col := entryOrigin(d, h, w)
+		dest.Clear()
+		place(from, dest, dr, dc)
+		place(to, dest, row+dr, col+dc)
+	}
+}
+
+// ScrollUp scrolls the starting frame off the top of the display, while the finishing frame follows it up from the bottom.
+func ScrollUp() Effect {
+	return Push(Up)
+}
+
+// Wipe reveals the finishing frame behind an edge that sweeps across the display in the given direction.
+// Unlike SlideIn, neither frame moves; each pixel is switched from one frame to the other as the edge passes.
+func Wipe(d Direction) Effect {
+	return func(dest, from, to *bits.Matrix, progress float64) {
+		h, w := dest.Size()
+		place(from, dest, 0, 0)
+		switch d {
+		case Up:
+			n := scale(h, progress)
+			region(to, dest, h-n, 0, n, w)
+		case Down:
+			region(to, dest, 0, 0, scale(h, progress), w)
+		case Left:
+			n := scale(w, progress)
+			region(to, dest, 0, w-n, h, n)
+		case Right:
+			region(to, dest, 0, 0, h, scale(w, progress))
+		}
+	}
+}
+
+// Dissolve switches pixels from the starting frame to the finishing frame one at a time, in a random order.
+// The order is determined by the seed, so that the same seed always produces the same sequence of frames.
+// The order is derived from the seed for each frame, so the effect holds no state, and may be used by several
+// transitions at once.
+func Dissolve(seed int64) Effect {
+	return func(dest, from, to *bits.Matrix, progress float64) {
+		h, w := dest.Size()
+		place(from, dest, 0, 0)
+		n := scale(h*w, progress)
+		if n == 0 {
+			return
+		}
+
+		order := rand.New(rand.NewSource(seed)).Perm(h * w)
+		for _, p := range order[:n] {
+			row, col := p/w, p%w
+			dest.Set(row, col, to.Get(row, col))
+		}
+	}
+}
+
+// Blinds reveals the finishing frame as a set of horizontal slats, each of the given height, which close simultaneously from the top down.
+//
+// Panics if the slat height is less than 1.
+func Blinds(slat int) Effect {
+	if slat < 1 {
+		panic("Slat height must be at least 1")
+	}
+
+	return func(dest, from, to *bits.Matrix, progress float64) {
+		h, w := dest.Size()
+		place(from, dest, 0, 0)
+		n := scale(slat, progress)
+		for row := 0; row < h; row += slat {
+			region(to, dest, row, 0, n, w)
+		}
+	}
+}
+
+// Returns the distance moved in the given direction after a given proportion of a transition.
+func displacement(d Direction, h, w int, progress float64) (row, col int) {
+	switch d {
+	case Up:
+		return -scale(h, progress), 0
+	case Down:
+		return scale(h, progress), 0
+	case Left:
+		return 0, -scale(w, progress)
+	case Right:
+		return 0, scale(w, progress)
+	}
+	panic("Unrecognised direction")
+}
+
+// Returns the location, just off the edge of the display, from which a frame enters when moving in the given direction.
+func entryOrigin(d Direction, h, w int) (row, col int) {
+	switch d {
+	case Up:
+		return h, 0
+	case Down:
+		return -h, 0
+	case Left:
+		return 0, w
+	case Right:
+		return 0, -w
+	}
+	panic("Unrecognised direction")
+}
+
+func scale(n int, progress float64) int {
+	if progress <= 0 {
+		return 0
+	}
+	if progress >= 1 {
+		return n
+	}
+	return int(float64(n) * progress)
+}
+
+// Copies the whole of the source matrix to the destination matrix, with the origin of the source at the given location.
+// The location may be outside the destination; any part of the source falling outside the destination is clipped.
+func place(source, dest *bits.Matrix, row, col int) {
+	h, w := source.Size()
+	copyClipped(source, 0, 0, dest, row, col, h, w)
+}
+
+// Copies a rectangle from the source matrix to the same location in the destination matrix.
+func region(source, dest *bits.Matrix, row, col, height, width int) {
+	copyClipped(source, row, col, dest, row, col, height, width)
+}
+
+func copyClipped(source *bits.Matrix, sourceRow, sourceCol int, dest *bits.Matrix, destRow, destCol, height, width int) {
+	if destRow < 0 {
+		sourceRow -= destRow
+		height += destRow
+		destRow = 0
+	}
+	if destCol < 0 {
+		sourceCol -= destCol
+		width += destCol
+		destCol = 0
+	}
+
+	sh, sw := source.Size()
+	dh, dw := dest.Size()
+	if height <= 0 || width <= 0 || sourceRow >= sh || sourceCol >= sw || destRow >= dh || destCol >= dw {
+		return
+	}
+
+	bits.Copy(source, sourceRow, sourceCol, dest, destRow, destCol, height, width)
+}
@@ -0,0 +1,4 @@
+// Package transition provides animated effects for switching a display.Canvas from one frame to another.
+// A transition is described by an Effect, which computes the intermediate frames between a starting and
+// a finishing bit matrix, and is played onto a canvas over a given duration using Start.
+package transition
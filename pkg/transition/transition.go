@@ -0,0 +1,100 @@
+package transition
+
+import (
+	"sync"
+	"time"
+
+	"github.com/realency/arke/pkg/bits"
+	"github.com/realency/arke/pkg/clock"
+	"github.com/realency/arke/pkg/display"
+)
+
+// FrameInterval is the interval between successive frames of a transition.
+var FrameInterval = 40 * time.Millisecond
+
+// A Transition is an effect being played onto a canvas.
+//
+// Transitions are created by Start, and run in the background until they either complete or are cancelled.
+type Transition struct {
+	clock     clock.Clock
+	cancel    chan struct{}
+	done      chan struct{}
+	once      sync.Once
+	completed bool
+}
+
+// Start begins playing an effect onto a canvas, switching it from one frame to another over a given duration.
+//
+// Frames are written to the canvas at the given location, and are clipped to the extent of the canvas.
+// Each frame is written as a single batch update, so that observers of the canvas are notified once per frame.
+// The starting and finishing frames must be the same size.  Panics if they are not, or if the location is out of bounds.
+// The transition is timed by the system clock, unless another is selected by an option.
+func Start(canvas *display.Canvas, row, col int, from, to *bits.Matrix, effect Effect, duration time.Duration, opts ...clock.Option) *Transition {
+	fh, fw := from.Size()
+	th, tw := to.Size()
+	if fh != th || fw != tw {
+		panic("Mismatched frame sizes in transition.Start")
+	}
+	ch, cw := canvas.Size()
+	if row < 0 || row >= ch || col < 0 || col >= cw {
+		panic("Arg out of bounds")
+	}
+
+	t := &Transition{
+		clock:  clock.Of(opts),
+		cancel: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go t.run(canvas, row, col, from, to, effect, duration)
+	return t
+}
+
+func (t *Transition) run(canvas *display.Canvas, row, col int, from, to *bits.Matrix, effect Effect, duration time.Duration) {
+	defer close(t.done)
+
+	h, w := from.Size()
+	frame := bits.NewMatrix(h, w)
+	render := func(progress float64) {
+		effect(frame, from, to, progress)
+		canvas.BeginUpdate()
+		defer canvas.EndUpdate()
+		canvas.Write(frame, row, col)
+	}
+
+	start := t.clock.Now()
+	render(0)
+	for {
+		select {
+		case <-t.cancel:
+			return
+		case now := <-t.clock.After(FrameInterval):
+			elapsed := now.Sub(start)
+			if elapsed >= duration {
+				render(1)
+				t.completed = true
+				return
+			}
+			render(float64(elapsed) / float64(duration))
+		}
+	}
+}
+
+// Cancel stops the transition, leaving the most recently rendered frame on the canvas.
+// Cancelling a transition that has already finished has no effect.
+func (t *Transition) Cancel() {
+	t.once.Do(func() {
+		close(t.cancel)
+	})
+}
+
+// Done returns a channel that is closed when the transition finishes, either by completing or by being cancelled.
+func (t *Transition) Done() <-chan struct{} {
+	return t.done
+}
+
+// Wait blocks until the transition finishes.
+// Returns true if the transition ran to completion, or false if it was cancelled.
+func (t *Transition) Wait() bool {
+	<-t.done
+	return t.completed
+}
@@ -0,0 +1,387 @@
+package viewport
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/realency/arke/pkg/display"
+)
+
+type attachState struct {
+	canvas   *display.Canvas
+	row, col int
+}
+
+// The state to which a child of a Broadcast is brought: its attachment, and the brightness and power of its display.
+type targetState struct {
+	attachState
+	bright int // Negative until the brightness is first set
+	asleep bool
+}
+
+// A broadcastTarget drives a single child of a Broadcast from its own goroutine, so that a slow or failing
+// child does not hold up the others.  Operations are coalesced: the goroutine brings the child up to date
+// with the most recently requested state, skipping any intermediate states it did not get round to.
+type broadcastTarget struct {
+	child    ViewPort // The ViewPort as added to the Broadcast
+	viewPort ViewPort // The ViewPort driven, which may wrap the child in a transform
+	mutex    sync.Mutex
+	desired  targetState
+	applied  targetState
+	signal   chan struct{}
+	done     chan struct{}
+	waiters  []chan struct{}
+}
+
+func newBroadcastTarget(child, vp ViewPort, s targetState) *broadcastTarget {
+	result := &broadcastTarget{
+		child:    child,
+		viewPort: vp,
+		applied:  targetState{attachState: attachState{row: -1, col: -1}, bright: -1},
+		signal:   make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	go result.run()
+	result.request(func(d *targetState) { *d = s })
+	return result
+}
+
+// Changes the state requested for the child, and wakes the goroutine driving it.
+func (t *broadcastTarget) request(change func(*targetState)) {
+	t.mutex.Lock()
+	change(&t.desired)
+	t.mutex.Unlock()
+
+	select {
+	case t.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (t *broadcastTarget) run() {
+	defer close(t.done)
+	for range t.signal {
+		t.mutex.Lock()
+		s := t.desired
+		t.mutex.Unlock()
+
+		t.apply(s)
+
+		t.mutex.Lock()
+		t.applied = s
+		if t.desired == t.applied {
+			for _, w := range t.waiters {
+				close(w)
+			}
+			t.waiters = nil
+		}
+		t.mutex.Unlock()
+	}
+}
+
+// Waits for the state most recently requested to be applied to the child, and then flushes the child.
+func (t *broadcastTarget) flush(ctx context.Context) error {
+	t.mutex.Lock()
+	if t.desired != t.applied {
+		w := make(chan struct{})
+		t.waiters = append(t.waiters, w)
+		t.mutex.Unlock()
+		select {
+		case <-w:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	} else {
+		t.mutex.Unlock()
+	}
+	return Flush(ctx, t.viewPort)
+}
+
+// Stops the goroutine driving the child, once it has applied the state most recently requested.
+func (t *broadcastTarget) stop() {
+	close(t.signal)
+	<-t.done
+}
+
+func (t *broadcastTarget) apply(s targetState) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("WARNING Broadcast child ViewPort failed:", r)
+		}
+	}()
+
+	switch {
+	case s.attachState == t.applied.attachState:
+	case s.canvas == nil:
+		t.viewPort.Detach()
+	case s.canvas != t.applied.canvas:
+		t.viewPort.Attach(s.canvas, s.row, s.col)
+	default:
+		t.viewPort.Locate(s.row, s.col)
+	}
+
+	if s.bright >= 0 && s.bright != t.applied.bright {
+		SetBrightness(t.viewPort, byte(s.bright))
+	}
+	if s.asleep != t.applied.asleep {
+		if s.asleep {
+			Sleep(t.viewPort)
+		} else {
+			Wake(t.viewPort)
+		}
+	}
+}
+
+// Broadcast is a ViewPort that shows the same region of a canvas on several child ViewPorts.
+//
+// Attach, Detach and Locate are forwarded to every child.  Each child is driven independently, so that
+// a child that is slow to respond, or that panics, does not prevent the others from being updated.
+// Children may be added with a Transform, for example to mirror content for a rear-facing panel, or to
+// rotate it for a display mounted on its side.  The size of a Broadcast is the smallest size enclosing
+// the regions framed by all of its children.
+type Broadcast struct {
+	mutex         sync.Mutex
+	targets       []*broadcastTarget
+	state         targetState
+	height, width int
+}
+
+// NewBroadcast returns a new instance of Broadcast, with no children.
+func NewBroadcast() *Broadcast {
+	return &Broadcast{
+		state: targetState{attachState: attachState{row: -1, col: -1}, bright: -1},
+	}
+}
+
+// Add adds a child ViewPort that shows the region framed by the broadcast unchanged.
+// If the broadcast is already attached to a canvas, the child is attached immediately.
+func (b *Broadcast) Add(vp ViewPort) {
+	b.add(vp, vp)
+}
+
+// AddTransformed adds a child ViewPort that shows the region framed by the broadcast with a transform applied.
+func (b *Broadcast) AddTransformed(vp ViewPort, t Transform) {
+	b.add(vp, NewTransformed(vp, t))
+}
+
+func (b *Broadcast) add(child, vp ViewPort) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	// The new child starts detached, and is brought to the brightness and power of the others
+	s := b.state
+	s.attachState = attachState{row: -1, col: -1}
+	b.targets = append(b.targets, newBroadcastTarget(child, vp, s))
+	b.resize()
+	if b.state.canvas != nil {
+		// The broadcast may have grown, so the location is clamped afresh as the new child is attached
+		b.update(b.state.attachState)
+	}
+}
+
+// Remove stops driving a child ViewPort, and detaches it.  The child is not closed.
+// Returns false if the ViewPort is not a child of the broadcast.
+func (b *Broadcast) Remove(vp ViewPort) bool {
+	b.mutex.Lock()
+	var target *broadcastTarget
+	for i, t := range b.targets {
+		if t.child == vp {
+			target = t
+			b.targets = append(b.targets[:i:i], b.targets[i+1:]...)
+			break
+		}
+	}
+	if target != nil {
+		b.resize()
+		target.request(func(d *targetState) { d.attachState = attachState{row: -1, col: -1} })
+	}
+	b.mutex.Unlock()
+
+	if target == nil {
+		return false
+	}
+	target.stop()
+	return true
+}
+
+// Recomputes the size of the broadcast from the sizes of its children.  Called with the mutex held.
+func (b *Broadcast) resize() {
+	b.height, b.width = 0, 0
+	for _, t := range b.targets {
+		h, w := t.viewPort.Size()
+		if h > b.height {
+			b.height = h
+		}
+		if w > b.width {
+			b.width = w
+		}
+	}
+}
+
+func (b *Broadcast) update(s attachState) {
+	if s.canvas != nil {
+		h, w := s.canvas.Size()
+		if s.row+b.height > h {
+			s.row = h - b.height
+		}
+		if s.row < 0 {
+			s.row = 0
+		}
+		if s.col+b.width > w {
+			s.col = w - b.width
+		}
+		if s.col < 0 {
+			s.col = 0
+		}
+	}
+
+	b.state.attachState = s
+	for _, t := range b.targets {
+		t.request(func(d *targetState) { d.attachState = s })
+	}
+}
+
+// Attach attaches every child ViewPort to a canvas at a specific location.
+func (b *Broadcast) Attach(canvas *display.Canvas, row, col int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if canvas == nil {
+		b.update(attachState{row: -1, col: -1})
+		return
+	}
+	b.update(attachState{canvas, row, col})
+}
+
+// Detach detaches every child ViewPort from the canvas it is currently attached to.
+func (b *Broadcast) Detach() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.update(attachState{row: -1, col: -1})
+}
+
+// Locate repositions every child ViewPort at a new position on the underlying canvas.
+func (b *Broadcast) Locate(row, col int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.state.canvas == nil {
+		return
+	}
+	b.update(attachState{b.state.canvas, row, col})
+}
+
+// Offset returns the current location of the broadcast - its offset into the canvas.
+func (b *Broadcast) Offset() (row, col int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.state.row, b.state.col
+}
+
+// Size returns the size of the region framed by the broadcast, in pixels.
+func (b *Broadcast) Size() (height, width int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.height, b.width
+}
+
+// Canvas returns the attached canvas, or nil if the broadcast is not attached to any canvas.
+func (b *Broadcast) Canvas() *display.Canvas {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.state.canvas
+}
+
+func (b *Broadcast) viewPorts() []ViewPort {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	result := make([]ViewPort, len(b.targets))
+	for i, t := range b.targets {
+		result[i] = t.viewPort
+	}
+	return result
+}
+
+// SupportsBrightness reports whether every child ViewPort supports brightness.
+func (b *Broadcast) SupportsBrightness() bool {
+	return every(b.viewPorts(), SupportsBrightness)
+}
+
+// SupportsPower reports whether every child ViewPort supports power.
+func (b *Broadcast) SupportsPower() bool {
+	return every(b.viewPorts(), SupportsPower)
+}
+
+// SetBrightness sets the brightness of each child ViewPort that supports brightness.
+// Like other operations, the change is applied to each child from the goroutine driving it.
+func (b *Broadcast) SetBrightness(bright byte) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.state.bright = int(bright)
+	for _, t := range b.targets {
+		t.request(func(d *targetState) { d.bright = int(bright) })
+	}
+}
+
+// Sleep puts each child ViewPort that supports power to sleep.
+func (b *Broadcast) Sleep() {
+	b.setAsleep(true)
+}
+
+// Wake wakes each child ViewPort that supports power.
+func (b *Broadcast) Wake() {
+	b.setAsleep(false)
+}
+
+func (b *Broadcast) setAsleep(asleep bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.state.asleep = asleep
+	for _, t := range b.targets {
+		t.request(func(d *targetState) { d.asleep = asleep })
+	}
+}
+
+// Asleep reports whether the broadcast has been put to sleep.  False unless every child supports power.
+func (b *Broadcast) Asleep() bool {
+	b.mutex.Lock()
+	asleep := b.state.asleep
+	b.mutex.Unlock()
+	return asleep && b.SupportsPower()
+}
+
+// Close stops driving the child ViewPorts, and closes each of them, returning the first error.
+// The broadcast must not be used after it is closed.
+func (b *Broadcast) Close() error {
+	b.mutex.Lock()
+	targets := b.targets
+	b.targets = nil
+	b.state.attachState = attachState{row: -1, col: -1}
+	b.mutex.Unlock()
+
+	viewPorts := make([]ViewPort, len(targets))
+	for i, t := range targets {
+		t.stop()
+		viewPorts[i] = t.viewPort
+	}
+	return closeAll(viewPorts)
+}
+
+// Flush waits for every child ViewPort to be brought up to date, and flushes each child that implements Flusher,
+// returning the first error.
+func (b *Broadcast) Flush(ctx context.Context) error {
+	b.mutex.Lock()
+	targets := append([]*broadcastTarget(nil), b.targets...)
+	b.mutex.Unlock()
+
+	for _, t := range targets {
+		if err := t.flush(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Err returns the first error reported by any child ViewPort, or nil if there is none.
+func (b *Broadcast) Err() error {
+	return firstErr(b.viewPorts())
+}
@@ -0,0 +1,340 @@
+package viewport
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/realency/arke/pkg/clock"
+)
+
+// CameraFrameInterval is the interval between successive movements of a ViewPort by a Camera.
+var CameraFrameInterval = 40 * time.Millisecond
+
+// Easing maps the proportion of the duration of a movement that has elapsed, in the range 0..1, to the proportion
+// of the distance covered.  An easing should map 0 to 0 and 1 to 1.
+type Easing func(progress float64) float64
+
+// Linear moves at a constant speed.
+func Linear(progress float64) float64 {
+	return progress
+}
+
+// EaseInOut accelerates from rest at the start of a movement, and decelerates to rest at the end.
+func EaseInOut(progress float64) float64 {
+	return (1 - math.Cos(math.Pi*progress)) / 2
+}
+
+// Bounce reaches the end of a movement early, and bounces back from it in ever smaller bounces before coming to
+// rest there, as a dropped ball does.  The movement never passes the end.
+func Bounce(progress float64) float64 {
+	const n, d = 7.5625, 2.75
+	switch {
+	case progress < 1/d:
+		return n * progress * progress
+	case progress < 2/d:
+		progress -= 1.5 / d
+		return n*progress*progress + 0.75
+	case progress < 2.5/d:
+		progress -= 2.25 / d
+		return n*progress*progress + 0.9375
+	default:
+		progress -= 2.625 / d
+		return n*progress*progress + 0.984375
+	}
+}
+
+// Point is a location on a canvas.
+type Point struct {
+	Row, Col int
+}
+
+// Camera moves a ViewPort over its canvas smoothly, along straight lines or paths, by panning, or by following
+// a moving target.
+//
+// By default, locations are clamped so that the ViewPort stays within the canvas.  A camera may instead wrap
+// locations around the edges of the canvas: to show content wrapping seamlessly across the edges, the ViewPort
+// should be a Transformed with Wrap set.  Each movement cancels any movement already in progress.
+type Camera struct {
+	mutex    sync.Mutex
+	viewPort ViewPort
+	clock    clock.Clock
+	wrap     bool
+	row, col float64
+	cancel   chan struct{}
+}
+
+// NewCamera returns a new instance of Camera, controlling the given ViewPort from its current location.
+// Movements are timed by the system clock, unless another is selected by an option.
+func NewCamera(vp ViewPort, opts ...clock.Option) *Camera {
+	row, col := vp.Offset()
+	if row < 0 || col < 0 {
+		row, col = 0, 0
+	}
+	return &Camera{
+		viewPort: vp,
+		clock:    clock.Of(opts),
+		row:      float64(row),
+		col:      float64(col),
+	}
+}
+
+// SetWrap sets whether locations wrap around the edges of the canvas, rather than being clamped to them.
+func (c *Camera) SetWrap(wrap bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.wrap = wrap
+}
+
+// Position returns the location of the ViewPort as last set by the camera.
+func (c *Camera) Position() (row, col int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return int(math.Round(c.row)), int(math.Round(c.col))
+}
+
+// Stop stops any movement in progress, leaving the ViewPort at its current location.
+func (c *Camera) Stop() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.stop()
+}
+
+func (c *Camera) stop() {
+	if c.cancel != nil {
+		close(c.cancel)
+		c.cancel = nil
+	}
+}
+
+// Jump moves the ViewPort to a location immediately.
+func (c *Camera) Jump(row, col int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.stop()
+	c.set(float64(row), float64(col))
+}
+
+// Returns the greatest location of the ViewPort within its canvas, or false if it is not attached.
+func (c *Camera) limits() (maxRow, maxCol float64, ok bool) {
+	canvas := c.viewPort.Canvas()
+	if canvas == nil {
+		return 0, 0, false
+	}
+	ch, cw := canvas.Size()
+	vh, vw := c.viewPort.Size()
+	return float64(ch - vh), float64(cw - vw), true
+}
+
+// Moves the ViewPort to a location, clamped or wrapped to the canvas.  Called with the mutex held.
+func (c *Camera) set(row, col float64) {
+	if canvas := c.viewPort.Canvas(); canvas != nil && c.wrap {
+		ch, cw := canvas.Size()
+		row, col = wrapTo(row, float64(ch)), wrapTo(col, float64(cw))
+	} else if maxRow, maxCol, ok := c.limits(); ok {
+		row, col = clampTo(row, maxRow), clampTo(col, maxCol)
+	}
+	c.row, c.col = row, col
+	c.viewPort.Locate(int(math.Round(row)), int(math.Round(col)))
+}
+
+func wrapTo(v, size float64) float64 {
+	v = math.Mod(v, size)
+	if v < 0 {
+		v += size
+	}
+	return v
+}
+
+// Returns the distance equivalent to d, around a loop of the given size, that is nearest to zero.
+func nearest(d, size float64) float64 {
+	d = wrapTo(d, size)
+	if d > size/2 {
+		d -= size
+	}
+	return d
+}
+
+func clampTo(v, max float64) float64 {
+	if v > max {
+		v = max
+	}
+	if v < 0 {
+		v = 0
+	}
+	return v
+}
+
+// MoveTo moves the ViewPort from its current location to another, along a straight line, over a given duration.
+// A nil easing is treated as Linear.  Returns a channel that is closed when the movement completes or is cancelled.
+func (c *Camera) MoveTo(row, col int, duration time.Duration, easing Easing) <-chan struct{} {
+	return c.MoveAlong([]Point{{row, col}}, duration, easing)
+}
+
+// MoveAlong moves the ViewPort from its current location through each of a series of waypoints in turn, along
+// straight lines, over a given duration.  The easing applies to the path as a whole, and the speed along the path
+// is otherwise constant, so that longer legs take longer.  A nil easing is treated as Linear.
+// Returns a channel that is closed when the movement completes or is cancelled.
+func (c *Camera) MoveAlong(waypoints []Point, duration time.Duration, easing Easing) <-chan struct{} {
+	if easing == nil {
+		easing = Linear
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.stop()
+
+	p := newPath(c.row, c.col, waypoints)
+	return c.start(func(elapsed time.Duration) (float64, float64, bool) {
+		if elapsed >= duration {
+			row, col := p.at(1)
+			return row, col, false
+		}
+		row, col := p.at(easing(float64(elapsed) / float64(duration)))
+		return row, col, true
+	})
+}
+
+// Pan moves the ViewPort continuously at a given speed, in pixels per second, which may be negative.
+// Continues until cancelled, or until the ViewPort can move no further if locations are clamped.
+// Returns a channel that is closed when panning stops.
+func (c *Camera) Pan(rowSpeed, colSpeed float64) <-chan struct{} {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.stop()
+
+	startRow, startCol := c.row, c.col
+	return c.start(func(elapsed time.Duration) (float64, float64, bool) {
+		row := startRow + rowSpeed*elapsed.Seconds()
+		col := startCol + colSpeed*elapsed.Seconds()
+		maxRow, maxCol, ok := c.limits()
+		if c.wrap || !ok {
+			return row, col, true
+		}
+		more := (rowSpeed < 0 && row > 0) || (rowSpeed > 0 && row < maxRow) ||
+			(colSpeed < 0 && col > 0) || (colSpeed > 0 && col < maxCol)
+		return row, col, more
+	})
+}
+
+// Follow keeps a moving target, such as a sprite, at the centre of the ViewPort, until cancelled.
+// The target function is called once per frame, and returns the location of the target on the canvas.
+// Each frame, the ViewPort moves the given proportion of its distance from the target: 1 tracks the target exactly,
+// while smaller values follow it smoothly, with a lag.  Returns a channel that is closed when the movement is cancelled.
+func (c *Camera) Follow(target func() (row, col int), smoothing float64) <-chan struct{} {
+	if smoothing <= 0 || smoothing > 1 {
+		smoothing = 1
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.stop()
+
+	return c.start(func(elapsed time.Duration) (float64, float64, bool) {
+		tr, tc := target()
+		h, w := c.viewPort.Size()
+		row := float64(tr - h/2)
+		col := float64(tc - w/2)
+		if canvas := c.viewPort.Canvas(); canvas != nil && c.wrap {
+			// Follow the target the short way around the canvas
+			ch, cw := canvas.Size()
+			row = c.row + nearest(row-c.row, float64(ch))
+			col = c.col + nearest(col-c.col, float64(cw))
+		}
+		return c.row + (row-c.row)*smoothing, c.col + (col-c.col)*smoothing, true
+	})
+}
+
+// A frame computes the location of the ViewPort after a given time has elapsed since the start of a movement,
+// and whether the movement continues.  Frames are called with the mutex held.
+type frame func(elapsed time.Duration) (row, col float64, more bool)
+
+// Runs a movement, setting the location of the ViewPort once per frame.  Called with the mutex held.
+func (c *Camera) start(f frame) <-chan struct{} {
+	cancel := make(chan struct{})
+	done := make(chan struct{})
+	c.cancel = cancel
+
+	// The first frame is applied immediately, so that a movement of no duration completes synchronously
+	row, col, more := f(0)
+	c.set(row, col)
+	if !more {
+		c.cancel = nil
+		close(done)
+		return done
+	}
+
+	go func() {
+		defer close(done)
+		start := c.clock.Now()
+
+		for {
+			var now time.Time
+			select {
+			case <-cancel:
+				return
+			case now = <-c.clock.After(CameraFrameInterval):
+			}
+
+			c.mutex.Lock()
+			select {
+			case <-cancel:
+				c.mutex.Unlock()
+				return
+			default:
+			}
+			row, col, more := f(now.Sub(start))
+			c.set(row, col)
+			if !more {
+				c.cancel = nil
+				c.mutex.Unlock()
+				return
+			}
+			c.mutex.Unlock()
+		}
+	}()
+
+	return done
+}
+
+// A path is a series of straight legs, parameterised by the proportion of its length covered.
+type path struct {
+	points  [][2]float64
+	lengths []float64
+	total   float64
+}
+
+func newPath(row, col float64, waypoints []Point) *path {
+	p := &path{points: [][2]float64{{row, col}}}
+	for _, w := range waypoints {
+		last := p.points[len(p.points)-1]
+		next := [2]float64{float64(w.Row), float64(w.Col)}
+		length := math.Hypot(next[0]-last[0], next[1]-last[1])
+		p.points = append(p.points, next)
+		p.lengths = append(p.lengths, length)
+		p.total += length
+	}
+	return p
+}
+
+// Returns the location at a proportion of the length of the path.  Proportions outside 0..1 are clamped.
+func (p *path) at(proportion float64) (row, col float64) {
+	end := p.points[len(p.points)-1]
+	if proportion >= 1 || p.total == 0 {
+		return end[0], end[1]
+	}
+	if proportion < 0 {
+		proportion = 0
+	}
+
+	distance := proportion * p.total
+	for i, length := range p.lengths {
+		if distance <= length && length > 0 {
+			from, to := p.points[i], p.points[i+1]
+			f := distance / length
+			return from[0] + (to[0]-from[0])*f, from[1] + (to[1]-from[1])*f
+		}
+		distance -= length
+	}
+	return end[0], end[1]
+}
@@ -0,0 +1,177 @@
+package viewport
+
+import "context"
+
+// Optional capabilities of a ViewPort.  Drivers implement whichever of these interfaces their displays support,
+// and generic code uses the helper functions below, which do nothing for viewports lacking the capability.
+// Viewports that combine others implement Combination as well, since whether they support a capability depends
+// on the viewports they combine.
+
+// Brightness is implemented by viewports whose displays can be dimmed.
+type Brightness interface {
+	// SetBrightness sets the brightness of the display in the range from 0 to 15.
+	SetBrightness(bright byte)
+}
+
+// Power is implemented by viewports whose displays can be put to sleep, blanking the display to save power.
+//
+// The viewport continues to track its canvas while asleep, so that on waking the display shows the current
+// content of the canvas without the canvas being redrawn.
+type Power interface {
+	// Sleep blanks the display.
+	Sleep()
+
+	// Wake restores the display after Sleep.
+	Wake()
+
+	// Asleep reports whether the display is asleep.
+	Asleep() bool
+}
+
+// Closer is implemented by viewports that hold resources, such as goroutines or hardware ports, that should be
+// released when the viewport is no longer needed.  A viewport must not be used after it is closed.
+type Closer interface {
+	// Close detaches the viewport, blanks the display and releases its resources.
+	Close() error
+}
+
+// Flusher is implemented by viewports that apply operations asynchronously.
+type Flusher interface {
+	// Flush blocks until every operation requested before the call has reached the display,
+	// or until the context is done, in which case it returns the error of the context.
+	Flush(ctx context.Context) error
+}
+
+// ErrorReporter is implemented by viewports whose displays may fail asynchronously, for example in writing to hardware.
+type ErrorReporter interface {
+	// Err returns the most recent error in driving the display, or nil if there has been none.
+	Err() error
+}
+
+// Combination is implemented by viewports, such as Composite, that combine other viewports.  A combination has
+// the methods of Brightness and Power, but supports each capability only if every viewport it combines does.
+type Combination interface {
+	// SupportsBrightness reports whether every viewport combined supports Brightness.
+	SupportsBrightness() bool
+
+	// SupportsPower reports whether every viewport combined supports Power.
+	SupportsPower() bool
+}
+
+// SupportsBrightness reports whether the display of a viewport can be dimmed: that is, whether the viewport
+// implements Brightness and, if it is a Combination, whether every viewport it combines does too.
+func SupportsBrightness(vp ViewPort) bool {
+	if _, ok := vp.(Brightness); !ok {
+		return false
+	}
+	if c, ok := vp.(Combination); ok {
+		return c.SupportsBrightness()
+	}
+	return true
+}
+
+// SupportsPower reports whether the display of a viewport can be put to sleep: that is, whether the viewport
+// implements Power and, if it is a Combination, whether every viewport it combines does too.
+func SupportsPower(vp ViewPort) bool {
+	if _, ok := vp.(Power); !ok {
+		return false
+	}
+	if c, ok := vp.(Combination); ok {
+		return c.SupportsPower()
+	}
+	return true
+}
+
+// SetBrightness sets the brightness of the display of a viewport, in the range 0..15.
+// Returns false, doing nothing, if the viewport does not support brightness.
+func SetBrightness(vp ViewPort, bright byte) bool {
+	if !SupportsBrightness(vp) {
+		return false
+	}
+	vp.(Brightness).SetBrightness(bright)
+	return true
+}
+
+// Sleep puts the display of a viewport to sleep.  Returns false, doing nothing, if the viewport does not support power.
+func Sleep(vp ViewPort) bool {
+	if !SupportsPower(vp) {
+		return false
+	}
+	vp.(Power).Sleep()
+	return true
+}
+
+// Wake wakes the display of a viewport, if it is asleep.  Returns false, doing nothing, if the viewport does not
+// support power.
+func Wake(vp ViewPort) bool {
+	if !SupportsPower(vp) {
+		return false
+	}
+	if p := vp.(Power); p.Asleep() {
+		p.Wake()
+	}
+	return true
+}
+
+// Asleep reports whether the display of a viewport is asleep.  Always false for a viewport that does not support power.
+func Asleep(vp ViewPort) bool {
+	return SupportsPower(vp) && vp.(Power).Asleep()
+}
+
+// Close closes a viewport, if it implements Closer.  Otherwise, it detaches the viewport, and returns nil.
+func Close(vp ViewPort) error {
+	if c, ok := vp.(Closer); ok {
+		return c.Close()
+	}
+	vp.Detach()
+	return nil
+}
+
+// Flush blocks until every operation requested of a viewport has reached its display, if the viewport implements Flusher.
+// Otherwise, it returns nil immediately.
+func Flush(ctx context.Context, vp ViewPort) error {
+	if f, ok := vp.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+// Err returns the most recent error in driving the display of a viewport, or nil if there has been none or the
+// viewport does not implement ErrorReporter.
+func Err(vp ViewPort) error {
+	if r, ok := vp.(ErrorReporter); ok {
+		return r.Err()
+	}
+	return nil
+}
+
+// Reports whether there is at least one viewport, and every one satisfies a condition.
+func every(viewPorts []ViewPort, condition func(ViewPort) bool) bool {
+	for _, vp := range viewPorts {
+		if !condition(vp) {
+			return false
+		}
+	}
+	return len(viewPorts) > 0
+}
+
+// Closes every viewport, returning the first error.
+func closeAll(viewPorts []ViewPort) error {
+	var result error
+	for _, vp := range viewPorts {
+		if err := Close(vp); err != nil && result == nil {
+			result = err
+		}
+	}
+	return result
+}
+
+// Returns the first error reported by any of the viewports.
+func firstErr(viewPorts []ViewPort) error {
+	for _, vp := range viewPorts {
+		if err := Err(vp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
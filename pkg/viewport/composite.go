@@ -0,0 +1,221 @@
+package viewport
+
+import (
+	"context"
+	"sync"
+
+	"github.com/realency/arke/pkg/display"
+)
+
+type placement struct {
+	viewPort ViewPort
+	row, col int
+}
+
+// Composite is a ViewPort made up of several child ViewPorts, each placed at a fixed position within one logical frame.
+//
+// Attaching a Composite to a canvas attaches each of its children to the same canvas, offset by the child's position,
+// so that a set of physical displays acts as a single large display.  The size of a Composite is the smallest
+// rectangle that encloses all of its children.
+type Composite struct {
+	mutex         sync.Mutex
+	children      []placement
+	canvas        *display.Canvas
+	row, col      int
+	height, width int
+}
+
+// NewComposite returns a new instance of Composite, with no children.
+func NewComposite() *Composite {
+	return &Composite{}
+}
+
+// Add places a child ViewPort at a given position within the frame of the composite.
+// If the composite is already attached to a canvas, the child is attached immediately.
+//
+// Panics if the position is negative.
+func (c *Composite) Add(vp ViewPort, row, col int) {
+	if row < 0 || col < 0 {
+		panic("Arg out of bounds")
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	p := placement{vp, row, col}
+	c.children = append(c.children, p)
+
+	h, w := vp.Size()
+	if row+h > c.height {
+		c.height = row + h
+	}
+	if col+w > c.width {
+		c.width = col + w
+	}
+
+	if c.canvas != nil {
+		// The composite may have grown, so existing children may need to move to keep it within the canvas
+		c.locate(c.row, c.col)
+		for _, p := range c.children[:len(c.children)-1] {
+			p.viewPort.Locate(c.row+p.row, c.col+p.col)
+		}
+		vp.Attach(c.canvas, c.row+row, c.col+col)
+	}
+}
+
+// Attach attaches each child ViewPort to a canvas, at the given location offset by the child's position.
+func (c *Composite) Attach(canvas *display.Canvas, row, col int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.canvas = canvas
+	if canvas == nil {
+		c.detach()
+		return
+	}
+
+	c.locate(row, col)
+	for _, p := range c.children {
+		p.viewPort.Attach(canvas, c.row+p.row, c.col+p.col)
+	}
+}
+
+// Detach detaches each child ViewPort from the canvas it is currently attached to.
+func (c *Composite) Detach() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.canvas = nil
+	c.detach()
+}
+
+func (c *Composite) detach() {
+	c.row, c.col = -1, -1
+	for _, p := range c.children {
+		p.viewPort.Detach()
+	}
+}
+
+// Locate repositions the composite at a new position on the underlying canvas, moving each child ViewPort with it.
+func (c *Composite) Locate(row, col int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.canvas == nil {
+		return
+	}
+
+	c.locate(row, col)
+	for _, p := range c.children {
+		p.viewPort.Locate(c.row+p.row, c.col+p.col)
+	}
+}
+
+// Clamps the location of the composite as a whole to the extent of the canvas, so that children
+// keep their relative positions at the edges of the canvas.
+func (c *Composite) locate(row, col int) {
+	h, w := c.canvas.Size()
+	if row+c.height > h {
+		row = h - c.height
+	}
+	if row < 0 {
+		row = 0
+	}
+	if col+c.width > w {
+		col = w - c.width
+	}
+	if col < 0 {
+		col = 0
+	}
+	c.row, c.col = row, col
+}
+
+// Offset returns the current location of the composite - its offset into the canvas.
+func (c *Composite) Offset() (row, col int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.row, c.col
+}
+
+// Size returns the size of the composite in pixels.
+func (c *Composite) Size() (height, width int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.height, c.width
+}
+
+// Canvas returns the attached canvas, or nil if the composite is not attached to any canvas.
+func (c *Composite) Canvas() *display.Canvas {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.canvas
+}
+
+func (c *Composite) viewPorts() []ViewPort {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	result := make([]ViewPort, len(c.children))
+	for i, p := range c.children {
+		result[i] = p.viewPort
+	}
+	return result
+}
+
+// SupportsBrightness reports whether every child ViewPort supports brightness.
+func (c *Composite) SupportsBrightness() bool {
+	return every(c.viewPorts(), SupportsBrightness)
+}
+
+// SupportsPower reports whether every child ViewPort supports power.
+func (c *Composite) SupportsPower() bool {
+	return every(c.viewPorts(), SupportsPower)
+}
+
+// SetBrightness sets the brightness of each child ViewPort that supports brightness.
+func (c *Composite) SetBrightness(bright byte) {
+	for _, vp := range c.viewPorts() {
+		SetBrightness(vp, bright)
+	}
+}
+
+// Sleep puts each child ViewPort that supports power to sleep.
+func (c *Composite) Sleep() {
+	for _, vp := range c.viewPorts() {
+		Sleep(vp)
+	}
+}
+
+// Wake wakes each child ViewPort that supports power.
+func (c *Composite) Wake() {
+	for _, vp := range c.viewPorts() {
+		Wake(vp)
+	}
+}
+
+// Asleep reports whether every child ViewPort is asleep.  False unless every child supports power.
+func (c *Composite) Asleep() bool {
+	return every(c.viewPorts(), Asleep)
+}
+
+// Close closes each child ViewPort, returning the first error.  The composite must not be used after it is closed.
+func (c *Composite) Close() error {
+	c.mutex.Lock()
+	c.canvas = nil
+	c.row, c.col = -1, -1
+	c.mutex.Unlock()
+	return closeAll(c.viewPorts())
+}
+
+// Err returns the first error reported by any child ViewPort, or nil if there is none.
+func (c *Composite) Err() error {
+	return firstErr(c.viewPorts())
+}
+
+// Flush flushes each child ViewPort that implements Flusher, returning the first error.
+func (c *Composite) Flush(ctx context.Context) error {
+	for _, vp := range c.viewPorts() {
+		if err := Flush(ctx, vp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
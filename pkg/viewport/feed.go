@@ -0,0 +1,109 @@
+package viewport
+
+import (
+	"context"
+	"sync"
+
+	"github.com/realency/arke/pkg/bits"
+	"github.com/realency/arke/pkg/display"
+)
+
+// Feed observes a canvas on behalf of a ViewPort, and passes each snapshot published by the canvas to the ViewPort
+// from a goroutine of its own, so that drawing does not hold up whoever is writing to the canvas.
+//
+// A ViewPort starts a new Feed each time it is attached to a canvas, and stops the previous one.  Snapshots queued
+// for an earlier attachment are discarded along with its feed, rather than being drawn in place of the canvas
+// now attached.
+type Feed struct {
+	canvas  *display.Canvas
+	id      uint64
+	updates chan *bits.Matrix
+	stop    chan struct{}
+	once    sync.Once
+	mutex   sync.Mutex
+	markers map[*bits.Matrix]chan struct{}
+}
+
+// NewFeed returns a new instance of Feed, observing a canvas, and the snapshot of the canvas as observation started.
+//
+// Draw is called with each later snapshot, from the goroutine of the feed, until the feed is stopped.  A snapshot may
+// be passed to draw as the feed is being stopped, so draw should check that the feed is still the ViewPort's own.
+func NewFeed(canvas *display.Canvas, buffer int, draw func(f *Feed, m *bits.Matrix)) (*Feed, *bits.Matrix) {
+	result := &Feed{
+		canvas:  canvas,
+		updates: make(chan *bits.Matrix, buffer),
+		stop:    make(chan struct{}),
+		markers: make(map[*bits.Matrix]chan struct{}),
+	}
+	var m *bits.Matrix
+	result.id, m = canvas.AddObserver(result.updates)
+	go result.run(draw)
+	return result, m
+}
+
+func (f *Feed) run(draw func(f *Feed, m *bits.Matrix)) {
+	for {
+		select {
+		case <-f.stop:
+			return
+		case m := <-f.updates:
+			if !f.marker(m) {
+				draw(f, m)
+			}
+		}
+	}
+}
+
+// Reports whether a snapshot is a marker queued by Flush, in which case the waiting Flush is released.
+func (f *Feed) marker(m *bits.Matrix) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	done, ok := f.markers[m]
+	if ok {
+		delete(f.markers, m)
+		close(done)
+	}
+	return ok
+}
+
+// Stop stops observing the canvas.  Snapshots not yet passed to draw are discarded.  Stopping a feed that has
+// already been stopped has no effect.
+func (f *Feed) Stop() {
+	f.once.Do(func() {
+		f.canvas.RemoveObserver(f.id)
+		close(f.stop)
+	})
+}
+
+// Flush blocks until every snapshot published before the call has been passed to draw, or until the feed is stopped,
+// or until the context is done, in which case it returns the error of the context.
+func (f *Feed) Flush(ctx context.Context) error {
+	// A marker is queued behind the pending snapshots, and is recognised by the goroutine of the feed
+	marker := bits.NewMatrix(1, 1)
+	done := make(chan struct{})
+	f.mutex.Lock()
+	f.markers[marker] = done
+	f.mutex.Unlock()
+
+	defer func() {
+		f.mutex.Lock()
+		delete(f.markers, marker)
+		f.mutex.Unlock()
+	}()
+
+	select {
+	case f.updates <- marker:
+	case <-f.stop:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-done:
+		return nil
+	case <-f.stop:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
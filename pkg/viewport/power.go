@@ -0,0 +1,135 @@
+package viewport
+
+import (
+	"sync"
+	"time"
+
+	"github.com/realency/arke/pkg/clock"
+)
+
+// Unknown state of a PowerSchedule, before it is first updated.
+const unknownPeriod = -2
+
+// A BlankingPeriod is a daily period during which a PowerSchedule blanks or dims its displays.
+type BlankingPeriod struct {
+	// From and To are times of day, as offsets from midnight.  A period for which To is earlier than From spans midnight.
+	From, To time.Duration
+
+	// Dim is true if displays are dimmed to Brightness during the period, rather than blanked
+	Dim        bool
+	Brightness byte
+}
+
+// Contains reports whether a time of day falls within the period.
+// The time of day is read from the wall clock, so that periods keep to local time on days when the clocks change.
+func (p BlankingPeriod) Contains(t time.Time) bool {
+	return clock.Between(clock.TimeOfDay(t), p.From, p.To)
+}
+
+// PowerSchedule blanks or dims a set of displays during daily periods, and restores them afterwards.
+//
+// Displays are blanked using Sleep, if the viewport supports power, and dimmed using SetBrightness, if the
+// viewport supports brightness.  Outside any period, displays are woken and set to the normal brightness of the
+// schedule.  Viewports supporting neither are left alone.
+type PowerSchedule struct {
+	mutex      sync.Mutex
+	viewPorts  []ViewPort
+	periods    []BlankingPeriod
+	brightness byte
+	applied    int
+	stop       chan struct{}
+}
+
+// NewPowerSchedule returns a new instance of PowerSchedule, for the given viewports at a normal brightness.
+func NewPowerSchedule(brightness byte, viewPorts ...ViewPort) *PowerSchedule {
+	return &PowerSchedule{
+		viewPorts:  viewPorts,
+		brightness: brightness,
+		applied:    unknownPeriod,
+	}
+}
+
+// Blank adds a daily period, from and to times of day given as offsets from midnight, during which displays are blanked.
+func (s *PowerSchedule) Blank(from, to time.Duration) {
+	s.add(BlankingPeriod{From: from, To: to})
+}
+
+// Dim adds a daily period, from and to times of day given as offsets from midnight, during which displays are dimmed.
+func (s *PowerSchedule) Dim(from, to time.Duration, brightness byte) {
+	s.add(BlankingPeriod{From: from, To: to, Dim: true, Brightness: brightness})
+}
+
+func (s *PowerSchedule) add(p BlankingPeriod) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.periods = append(s.periods, p)
+	s.applied = unknownPeriod
+}
+
+// Update brings the displays into the state scheduled for a given time.  Where periods overlap, the period added first applies.
+// Displays are changed only when the scheduled state differs from the state last applied.
+func (s *PowerSchedule) Update(now time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	current := -1
+	for i, p := range s.periods {
+		if p.Contains(now) {
+			current = i
+			break
+		}
+	}
+	if current == s.applied {
+		return
+	}
+	s.applied = current
+
+	for _, vp := range s.viewPorts {
+		switch {
+		case current < 0:
+			SetBrightness(vp, s.brightness)
+			Wake(vp)
+		case s.periods[current].Dim:
+			SetBrightness(vp, s.periods[current].Brightness)
+			Wake(vp)
+		default:
+			Sleep(vp)
+		}
+	}
+}
+
+// Start updates the displays immediately, and then at the given interval, until Stop is called.
+// The time is read from the system clock, unless another is selected by an option.
+func (s *PowerSchedule) Start(interval time.Duration, opts ...clock.Option) {
+	clk := clock.Of(opts)
+	s.mutex.Lock()
+	if s.stop != nil {
+		s.mutex.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	s.stop = stop
+	s.mutex.Unlock()
+
+	s.Update(clk.Now())
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-clk.After(interval):
+				s.Update(now)
+			}
+		}
+	}()
+}
+
+// Stop stops updating the displays, leaving them in their current state.
+func (s *PowerSchedule) Stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
@@ -0,0 +1,335 @@
+package viewport
+
+import (
+	"context"
+	"sync"
+
+	"github.com/realency/arke/pkg/bits"
+	"github.com/realency/arke/pkg/display"
+)
+
+// Rotation represents a clockwise rotation of content, in multiples of 90 degrees.
+type Rotation int
+
+// Constant values for rotations.
+const (
+	Rotate0   Rotation = 0
+	Rotate90  Rotation = 1
+	Rotate180 Rotation = 2
+	Rotate270 Rotation = 3
+)
+
+// Transform describes a change to the orientation of content between a canvas and a display.
+//
+// Mirroring is applied first, followed by rotation and then inversion.  The zero value of Transform leaves content unchanged.
+type Transform struct {
+	// Rotation rotates the content clockwise as shown on the display
+	Rotation Rotation
+
+	// MirrorHorizontal reverses the content from left to right
+	MirrorHorizontal bool
+
+	// MirrorVertical reverses the content from top to bottom
+	MirrorVertical bool
+
+	// Invert turns lit pixels off and unlit pixels on
+	Invert bool
+
+	// Wrap lets the region framed by a Transformed wrap around the edges of the canvas, rather than being clamped
+	// to them, as though opposite edges of the canvas were joined.  It has no effect on Apply.
+	Wrap bool
+}
+
+// Size returns the size of a matrix of the given size after transformation.
+// Rotating by 90 or 270 degrees swaps height and width.
+func (t Transform) Size(height, width int) (int, int) {
+	if t.Rotation%2 == 1 {
+		return width, height
+	}
+	return height, width
+}
+
+// Apply returns a transformed copy of a bit matrix.
+func (t Transform) Apply(m *bits.Matrix) *bits.Matrix {
+	h, w := m.Size()
+	rh, rw := t.Size(h, w)
+	result := bits.NewMatrix(rh, rw)
+
+	for r := 0; r < h; r++ {
+		for c := 0; c < w; c++ {
+			if !m.Get(r, c) {
+				continue
+			}
+			row, col := r, c
+			if t.MirrorHorizontal {
+				col = w - 1 - col
+			}
+			if t.MirrorVertical {
+				row = h - 1 - row
+			}
+			switch t.Rotation {
+			case Rotate90:
+				row, col = col, h-1-row
+			case Rotate180:
+				row, col = h-1-row, w-1-col
+			case Rotate270:
+				row, col = w-1-col, row
+			}
+			result.Set(row, col, true)
+		}
+	}
+
+	if t.Invert {
+		result.Not()
+	}
+	return result
+}
+
+// Transformed is a ViewPort that applies a Transform to a region of a canvas before passing it to a wrapped ViewPort,
+// for example to correct for a display that is mounted upside down, or viewed through a mirror.
+//
+// The wrapped ViewPort, which may be of any type, is attached to a private canvas of its own size, which is kept up to
+// date with the transformed content of the region framed on the source canvas.  The size of a Transformed is the size
+// of that region, so a display rotated by 90 or 270 degrees frames a region with its height and width swapped.
+// If the transform wraps, the region may be located anywhere, and locations are reduced to within the canvas.
+type Transformed struct {
+	mutex     sync.Mutex
+	inner     ViewPort
+	transform Transform
+	proxy     *display.Canvas
+	canvas    *display.Canvas
+	feed      *Feed
+	row, col  int
+	closed    bool
+}
+
+// NewTransformed returns a new instance of Transformed, showing content on a wrapped ViewPort with a transform applied.
+// The wrapped ViewPort should not be attached to any canvas, and is attached and detached along with the Transformed.
+func NewTransformed(inner ViewPort, t Transform) *Transformed {
+	h, w := inner.Size()
+	return &Transformed{
+		inner:     inner,
+		transform: t,
+		proxy:     display.NewCanvas(h, w),
+		row:       -1,
+		col:       -1,
+	}
+}
+
+// Renders a snapshot from a feed, unless the feed has since been replaced by a later attachment.
+func (t *Transformed) draw(f *Feed, m *bits.Matrix) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if f == t.feed {
+		t.render(m)
+	}
+}
+
+// Renders the framed region of a canvas snapshot to the proxy canvas.
+func (t *Transformed) render(m *bits.Matrix) {
+	if mh, mw := m.Size(); mh == 0 || mw == 0 {
+		return
+	}
+
+	h, w := t.Size()
+	region := bits.NewMatrix(h, w)
+	if t.transform.Wrap {
+		wrappedCopy(m, t.row, t.col, region)
+	} else {
+		bits.Copy(m, t.row, t.col, region, 0, 0, h, w)
+	}
+	t.proxy.Write(t.transform.Apply(region), 0, 0)
+}
+
+// Fills a destination matrix from a source matrix, starting at a given location and wrapping around its edges.
+func wrappedCopy(source *bits.Matrix, row, col int, dest *bits.Matrix) {
+	sh, sw := source.Size()
+	dh, dw := dest.Size()
+	for r := 0; r < dh; {
+		sr := (row + r) % sh
+		n := min(sh-sr, dh-r)
+		for c := 0; c < dw; {
+			sc := (col + c) % sw
+			k := min(sw-sc, dw-c)
+			bits.Copy(source, sr, sc, dest, r, c, n, k)
+			c += k
+		}
+		r += n
+	}
+}
+
+// Returns the non-negative remainder of a divided by b.
+func modulo(a, b int) int {
+	if r := a % b; r < 0 {
+		return r + b
+	}
+	return a % b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func (t *Transformed) locate(row, col int) {
+	h, w := t.canvas.Size()
+	if t.transform.Wrap {
+		t.row, t.col = modulo(row, h), modulo(col, w)
+		return
+	}
+	vh, vw := t.Size()
+	if row+vh > h {
+		row = h - vh
+	}
+	if row < 0 {
+		row = 0
+	}
+	if col+vw > w {
+		col = w - vw
+	}
+	if col < 0 {
+		col = 0
+	}
+	t.row, t.col = row, col
+}
+
+// Attach attaches the ViewPort to a canvas at a specific location, so that changes in region framed by the canvas are reflected in the display.
+func (t *Transformed) Attach(canvas *display.Canvas, row, col int) {
+	if canvas == nil {
+		t.Detach()
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.canvas != nil {
+		t.feed.Stop()
+	} else {
+		t.inner.Attach(t.proxy, 0, 0)
+	}
+
+	var m *bits.Matrix
+	t.canvas = canvas
+	t.feed, m = NewFeed(canvas, 20, t.draw)
+	t.locate(row, col)
+	t.render(m)
+}
+
+// Detach detaches the ViewPort from the canvas it is currently attached to.
+func (t *Transformed) Detach() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.canvas == nil {
+		return
+	}
+	t.feed.Stop()
+	t.canvas = nil
+	t.feed = nil
+	t.row, t.col = -1, -1
+	t.inner.Detach()
+}
+
+// Locate repositions the ViewPort at a new position on the underlying canvas.
+func (t *Transformed) Locate(row, col int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.canvas == nil {
+		return
+	}
+	t.locate(row, col)
+	t.render(t.canvas.Matrix())
+}
+
+// Offset returns the current location of the ViewPort - its offset into the canvas.
+func (t *Transformed) Offset() (row, col int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.row, t.col
+}
+
+// Size returns the size of the region of the canvas framed by the ViewPort, before it is transformed.
+func (t *Transformed) Size() (height, width int) {
+	return t.transform.Size(t.inner.Size())
+}
+
+// Canvas returns the attached canvas, or nil if the ViewPort is not attached to any canvas.
+func (t *Transformed) Canvas() *display.Canvas {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.canvas
+}
+
+// SupportsBrightness reports whether the wrapped ViewPort supports brightness.
+func (t *Transformed) SupportsBrightness() bool {
+	return SupportsBrightness(t.inner)
+}
+
+// SupportsPower reports whether the wrapped ViewPort supports power.
+func (t *Transformed) SupportsPower() bool {
+	return SupportsPower(t.inner)
+}
+
+// SetBrightness sets the brightness of the wrapped ViewPort, if it supports brightness.
+func (t *Transformed) SetBrightness(bright byte) {
+	SetBrightness(t.inner, bright)
+}
+
+// Sleep puts the wrapped ViewPort to sleep, if it supports power.
+func (t *Transformed) Sleep() {
+	Sleep(t.inner)
+}
+
+// Wake wakes the wrapped ViewPort, if it supports power.
+func (t *Transformed) Wake() {
+	Wake(t.inner)
+}
+
+// Asleep reports whether the wrapped ViewPort is asleep.
+func (t *Transformed) Asleep() bool {
+	return Asleep(t.inner)
+}
+
+// Close detaches the ViewPort, stops transforming updates, and closes the wrapped ViewPort.
+func (t *Transformed) Close() error {
+	t.mutex.Lock()
+	if t.closed {
+		t.mutex.Unlock()
+		return nil
+	}
+	t.closed = true
+	if t.canvas != nil {
+		t.feed.Stop()
+		t.canvas = nil
+		t.feed = nil
+		t.row, t.col = -1, -1
+	}
+	t.mutex.Unlock()
+	return Close(t.inner)
+}
+
+// Flush blocks until every update to the canvas made before the call has been transformed, and then flushes the wrapped ViewPort.
+func (t *Transformed) Flush(ctx context.Context) error {
+	t.mutex.Lock()
+	feed, closed := t.feed, t.closed
+	t.mutex.Unlock()
+
+	if closed {
+		return nil
+	}
+	if feed != nil {
+		if err := feed.Flush(ctx); err != nil {
+			return err
+		}
+	}
+	return Flush(ctx, t.inner)
+}
+
+// Err returns the most recent error reported by the wrapped ViewPort.
+func (t *Transformed) Err() error {
+	return Err(t.inner)
+}
@@ -0,0 +1,136 @@
+package widget
+
+import (
+	"github.com/realency/arke/pkg/bits"
+	"github.com/realency/arke/pkg/display"
+)
+
+// ProgressBar is a widget that displays a single value as a proportion of its width.
+//
+// Where the widget is at least three pixels high and wide, the bar is drawn within a one-pixel outline.
+type ProgressBar struct {
+	base
+	value float64
+}
+
+// NewProgressBar returns a new instance of ProgressBar, initially empty.
+func NewProgressBar() *ProgressBar {
+	return &ProgressBar{}
+}
+
+// Mount places the progress bar on a canvas within the given rectangle, and draws it.
+func (p *ProgressBar) Mount(canvas *display.Canvas, bounds Rect) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.mount(canvas, bounds)
+	p.render(p.draw)
+}
+
+// SetValue sets the progress displayed by the bar, in the range 0..1, inclusive.
+// Values out of range are clamped.
+func (p *ProgressBar) SetValue(value float64) {
+	if value < 0 {
+		value = 0
+	}
+	if value > 1 {
+		value = 1
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if value == p.value {
+		return
+	}
+	p.value = value
+	p.render(p.draw)
+}
+
+func (p *ProgressBar) draw(m *bits.Matrix) {
+	h, w := m.Size()
+	if h < 3 || w < 3 {
+		fill(m, 0, 0, h, proportion(p.value, 1, w))
+		return
+	}
+
+	fill(m, 0, 0, 1, w)
+	fill(m, h-1, 0, 1, w)
+	fill(m, 0, 0, h, 1)
+	fill(m, 0, w-1, h, 1)
+	fill(m, 1, 1, h-2, proportion(p.value, 1, w-2))
+}
+
+// BarGraph is a widget that displays a set of values as bars, each proportional to a maximum value.
+type BarGraph struct {
+	base
+	orientation Orientation
+	max         float64
+	values      []float64
+}
+
+// NewBarGraph returns a new instance of BarGraph.
+// The orientation determines the direction along which bars grow, and max is the value represented by a full-length bar.
+func NewBarGraph(orientation Orientation, max float64) *BarGraph {
+	return &BarGraph{
+		orientation: orientation,
+		max:         max,
+	}
+}
+
+// Mount places the bar graph on a canvas within the given rectangle, and draws it.
+func (g *BarGraph) Mount(canvas *display.Canvas, bounds Rect) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.mount(canvas, bounds)
+	g.render(g.draw)
+}
+
+// SetValues sets the values displayed by the graph, one bar per value.
+// The bars share the available space equally, separated by a one-pixel gap.
+func (g *BarGraph) SetValues(values ...float64) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if equalValues(values, g.values) {
+		return
+	}
+	g.values = append(g.values[:0], values...)
+	g.render(g.draw)
+}
+
+func (g *BarGraph) draw(m *bits.Matrix) {
+	n := len(g.values)
+	if n == 0 {
+		return
+	}
+
+	h, w := m.Size()
+	across, along := w, h
+	if g.orientation == Horizontal {
+		across, along = h, w
+	}
+
+	for i, v := range g.values {
+		start := across * i / n
+		end := across*(i+1)/n - 1
+		if end <= start {
+			end = start + 1
+		}
+		length := proportion(v, g.max, along)
+		if g.orientation == Horizontal {
+			fill(m, start, 0, end-start, length)
+		} else {
+			fill(m, h-length, start, length, end-start)
+		}
+	}
+}
+
+func equalValues(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
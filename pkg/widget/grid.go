@@ -0,0 +1,90 @@
+package widget
+
+import (
+	"sync"
+
+	"github.com/realency/arke/pkg/display"
+)
+
+type cell struct {
+	widget           Widget
+	row, col         int
+	rowSpan, colSpan int
+}
+
+// Grid is a container widget that lays out child widgets in a grid of equally sized rows and columns.
+// Since a Grid is itself a widget, grids may be nested to produce more complex layouts.
+type Grid struct {
+	mutex      sync.Mutex
+	rows, cols int
+	cells      []cell
+	canvas     *display.Canvas
+	bounds     Rect
+}
+
+// NewGrid returns a new instance of Grid with the given number of rows and columns.
+//
+// Panics if either argument is less than 1.
+func NewGrid(rows, cols int) *Grid {
+	if rows < 1 || cols < 1 {
+		panic("Arg out of bounds")
+	}
+	return &Grid{
+		rows: rows,
+		cols: cols,
+	}
+}
+
+// Add places a widget in the cell at the given row and column of the grid.
+func (g *Grid) Add(w Widget, row, col int) {
+	g.AddSpan(w, row, col, 1, 1)
+}
+
+// AddSpan places a widget in the grid, covering a rectangle of cells with its top-left cell at the given row and column.
+// If the grid is already mounted, the widget is mounted immediately.
+//
+// Panics if the rectangle of cells is not within the grid.
+func (g *Grid) AddSpan(w Widget, row, col, rowSpan, colSpan int) {
+	if row < 0 || col < 0 || rowSpan < 1 || colSpan < 1 || row+rowSpan > g.rows || col+colSpan > g.cols {
+		panic("Arg out of bounds")
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	c := cell{w, row, col, rowSpan, colSpan}
+	g.cells = append(g.cells, c)
+	if g.canvas != nil {
+		w.Mount(g.canvas, g.cellBounds(c))
+	}
+}
+
+// Mount places the grid on a canvas within the given rectangle, and mounts each of its child widgets within its cell.
+func (g *Grid) Mount(canvas *display.Canvas, bounds Rect) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.canvas = canvas
+	g.bounds = bounds
+	for _, c := range g.cells {
+		c.widget.Mount(canvas, g.cellBounds(c))
+	}
+}
+
+// Bounds returns the rectangle occupied by the grid.
+func (g *Grid) Bounds() Rect {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.bounds
+}
+
+func (g *Grid) cellBounds(c cell) Rect {
+	top := g.bounds.Height * c.row / g.rows
+	bottom := g.bounds.Height * (c.row + c.rowSpan) / g.rows
+	left := g.bounds.Width * c.col / g.cols
+	right := g.bounds.Width * (c.col + c.colSpan) / g.cols
+	return Rect{
+		Row:    g.bounds.Row + top,
+		Col:    g.bounds.Col + left,
+		Height: bottom - top,
+		Width:  right - left,
+	}
+}
@@ -0,0 +1,145 @@
+package widget
+
+import (
+	"time"
+
+	"github.com/realency/arke/pkg/bits"
+	"github.com/realency/arke/pkg/clock"
+	"github.com/realency/arke/pkg/display"
+)
+
+// Icon is a widget that displays a fixed image.
+// The image is drawn from the top-left corner of the widget, and clipped to its extent.
+type Icon struct {
+	base
+	image *bits.Matrix
+}
+
+// NewIcon returns a new instance of Icon, displaying the given image.
+func NewIcon(image *bits.Matrix) *Icon {
+	return &Icon{
+		image: image,
+	}
+}
+
+// Mount places the icon on a canvas within the given rectangle, and draws it.
+func (i *Icon) Mount(canvas *display.Canvas, bounds Rect) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.mount(canvas, bounds)
+	i.render(i.draw)
+}
+
+// SetImage changes the image displayed by the icon.
+func (i *Icon) SetImage(image *bits.Matrix) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if image == i.image {
+		return
+	}
+	i.image = image
+	i.render(i.draw)
+}
+
+func (i *Icon) draw(m *bits.Matrix) {
+	if i.image == nil {
+		return
+	}
+	h, w := i.image.Size()
+	if h > 0 && w > 0 {
+		bits.Copy(i.image, 0, 0, m, 0, 0, h, w)
+	}
+}
+
+// Indicator is a widget that is either lit or unlit, and may blink while lit.
+// A lit indicator fills its whole rectangle.
+type Indicator struct {
+	base
+	clock   clock.Clock
+	on      bool
+	visible bool
+	stop    chan struct{}
+}
+
+// NewIndicator returns a new instance of Indicator, initially unlit.
+// Blinking is timed by the system clock, unless another is selected by an option.
+func NewIndicator(opts ...clock.Option) *Indicator {
+	return &Indicator{
+		clock:   clock.Of(opts),
+		visible: true,
+	}
+}
+
+// Mount places the indicator on a canvas within the given rectangle, and draws it.
+func (d *Indicator) Mount(canvas *display.Canvas, bounds Rect) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.mount(canvas, bounds)
+	d.render(d.draw)
+}
+
+// SetOn lights or extinguishes the indicator.
+func (d *Indicator) SetOn(on bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if on == d.on {
+		return
+	}
+	d.on = on
+	d.visible = true
+	d.render(d.draw)
+}
+
+// SetBlinking causes a lit indicator to blink, switching between visible and invisible with the given period.
+// A period of zero or less stops the indicator blinking.
+func (d *Indicator) SetBlinking(period time.Duration) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.stop != nil {
+		close(d.stop)
+		d.stop = nil
+	}
+	if !d.visible {
+		d.visible = true
+		d.render(d.draw)
+	}
+	if period <= 0 {
+		return
+	}
+
+	stop := make(chan struct{})
+	d.stop = stop
+	go func() {
+		clk := d.clock
+		for {
+			select {
+			case <-stop:
+				return
+			case <-clk.After(period):
+				d.toggle(stop)
+			}
+		}
+	}()
+}
+
+// Toggles the visibility of the indicator, unless the blinking that requested it has since been stopped.
+func (d *Indicator) toggle(stop chan struct{}) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	select {
+	case <-stop:
+		return
+	default:
+	}
+	d.visible = !d.visible
+	if d.on {
+		d.render(d.draw)
+	}
+}
+
+func (d *Indicator) draw(m *bits.Matrix) {
+	if d.on && d.visible {
+		m.Not()
+	}
+}
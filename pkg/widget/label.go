@@ -0,0 +1,142 @@
+package widget
+
+import (
+	"time"
+
+	"github.com/realency/arke/pkg/bits"
+	"github.com/realency/arke/pkg/clock"
+	"github.com/realency/arke/pkg/display"
+	"github.com/realency/arke/pkg/font"
+)
+
+// Label is a widget that displays a single line of text.
+type Label struct {
+	base
+	font  display.Font
+	text  string
+	align Alignment
+}
+
+// NewLabel returns a new instance of Label, which renders text using the given font.
+func NewLabel(font display.Font, text string) *Label {
+	return &Label{
+		font: font,
+		text: text,
+	}
+}
+
+// Mount places the label on a canvas within the given rectangle, and draws it.
+func (l *Label) Mount(canvas *display.Canvas, bounds Rect) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.mount(canvas, bounds)
+	l.render(l.draw)
+}
+
+// Text returns the text currently displayed by the label.
+func (l *Label) Text() string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.text
+}
+
+// SetText changes the text displayed by the label.
+func (l *Label) SetText(text string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if text == l.text {
+		return
+	}
+	l.text = text
+	l.render(l.draw)
+}
+
+// SetAlignment changes the horizontal alignment of the text within the label.
+func (l *Label) SetAlignment(align Alignment) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if align == l.align {
+		return
+	}
+	l.align = align
+	l.render(l.draw)
+}
+
+func (l *Label) draw(m *bits.Matrix) {
+	line := font.Render(l.font, l.text)
+	lh, lw := line.Size()
+
+	_, width := m.Size()
+	col := 0
+	switch l.align {
+	case AlignCenter:
+		col = (width - lw) / 2
+	case AlignRight:
+		col = width - lw
+	}
+	if col < 0 {
+		col = 0
+	}
+
+	if lh > 0 && lw > 0 {
+		bits.Copy(line, 0, 0, m, 0, col, lh, lw)
+	}
+}
+
+// Clock is a widget that displays the time of day.
+type Clock struct {
+	*Label
+	clock  clock.Clock
+	layout string
+	stop   chan struct{}
+}
+
+// NewClock returns a new instance of Clock, which renders the time using the given font and time.Format layout.
+// Start takes the time from the system clock, unless another is selected by an option.
+func NewClock(font display.Font, layout string, opts ...clock.Option) *Clock {
+	return &Clock{
+		Label:  NewLabel(font, ""),
+		clock:  clock.Of(opts),
+		layout: layout,
+	}
+}
+
+// Update sets the time displayed by the clock.
+// The clock is redrawn only if the formatted time differs from the time currently displayed.
+func (c *Clock) Update(now time.Time) {
+	c.SetText(now.Format(c.layout))
+}
+
+// Start updates the clock with the current time at the given interval, until Stop is called.
+func (c *Clock) Start(interval time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.stop != nil {
+		return
+	}
+	stop := make(chan struct{})
+	c.stop = stop
+
+	go func() {
+		clk := c.clock
+		c.Update(clk.Now())
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-clk.After(interval):
+				c.Update(now)
+			}
+		}
+	}()
+}
+
+// Stop ends updates started by Start.
+func (c *Clock) Stop() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.stop != nil {
+		close(c.stop)
+		c.stop = nil
+	}
+}
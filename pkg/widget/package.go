@@ -0,0 +1,4 @@
+// Package widget provides a small toolkit of display elements, such as labels, clocks and bar graphs, drawn on a display.Canvas.
+// Each widget owns a rectangle of the canvas and redraws that rectangle when its value changes.
+// Widgets may be composed into a layout using a Grid, which is itself a widget.
+package widget
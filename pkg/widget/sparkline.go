@@ -0,0 +1,89 @@
+package widget
+
+import (
+	"math"
+
+	"github.com/realency/arke/pkg/bits"
+	"github.com/realency/arke/pkg/display"
+)
+
+// Sparkline is a widget that displays a history of recent values as a line, one column per value.
+//
+// The most recent value is drawn in the rightmost column.  Values are scaled vertically between the
+// minimum and maximum of the values currently displayed.
+// Values that are not finite, such as NaN, leave their column blank.
+type Sparkline struct {
+	base
+	values []float64
+}
+
+// NewSparkline returns a new instance of Sparkline, with no values.
+func NewSparkline() *Sparkline {
+	return &Sparkline{}
+}
+
+// Mount places the sparkline on a canvas within the given rectangle, and draws it.
+func (s *Sparkline) Mount(canvas *display.Canvas, bounds Rect) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.mount(canvas, bounds)
+	s.trim()
+	s.render(s.draw)
+}
+
+// Push adds a value to the history, discarding the oldest value if the history is wider than the widget.
+func (s *Sparkline) Push(value float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.values = append(s.values, value)
+	s.trim()
+	s.render(s.draw)
+}
+
+// Values returns the history of values currently held, oldest first.
+func (s *Sparkline) Values() []float64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]float64(nil), s.values...)
+}
+
+func (s *Sparkline) trim() {
+	if s.canvas == nil {
+		return
+	}
+	if extra := len(s.values) - s.bounds.Width; extra > 0 {
+		s.values = append(s.values[:0], s.values[extra:]...)
+	}
+}
+
+func (s *Sparkline) draw(m *bits.Matrix) {
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, v := range s.values {
+		if !finite(v) {
+			continue
+		}
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+
+	h, w := m.Size()
+	col := w - len(s.values)
+	for _, v := range s.values {
+		if finite(v) {
+			level := 0
+			if max > min {
+				level = int((v - min) / (max - min) * float64(h-1))
+			}
+			m.Set(h-1-level, col, true)
+		}
+		col++
+	}
+}
+
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
@@ -0,0 +1,108 @@
+package widget
+
+import (
+	"sync"
+
+	"github.com/realency/arke/pkg/bits"
+	"github.com/realency/arke/pkg/display"
+)
+
+// Rect represents a rectangle of pixels on a canvas.
+type Rect struct {
+	Row, Col      int
+	Height, Width int
+}
+
+// Widget is the interface for elements that draw themselves within a rectangle of a canvas.
+type Widget interface {
+	// Mount places the widget on a canvas within the given rectangle, and draws it.
+	// Until mounted, a widget records changes to its value but does not draw anything.
+	Mount(canvas *display.Canvas, bounds Rect)
+
+	// Bounds returns the rectangle occupied by the widget.
+	Bounds() Rect
+}
+
+// Alignment indicates how content is positioned horizontally within a widget.
+type Alignment int
+
+// Constant values for horizontal alignment.
+const (
+	AlignLeft   Alignment = 0
+	AlignCenter Alignment = 1
+	AlignRight  Alignment = 2
+)
+
+// Orientation indicates the direction along which a bar grows.
+type Orientation int
+
+// Constant values for the orientation of bars.
+const (
+	// Bars grow from left to right
+	Horizontal Orientation = 0
+
+	// Bars grow from bottom to top
+	Vertical Orientation = 1
+)
+
+// base provides the mounting and drawing logic common to all widgets.
+// Widgets embedding base should hold the mutex while modifying their state or drawing.
+type base struct {
+	mutex  sync.Mutex
+	canvas *display.Canvas
+	bounds Rect
+}
+
+// Bounds returns the rectangle occupied by the widget.
+func (b *base) Bounds() Rect {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.bounds
+}
+
+func (b *base) mount(canvas *display.Canvas, bounds Rect) {
+	b.canvas = canvas
+	b.bounds = bounds
+}
+
+// Draws the widget's rectangle in a single write to the canvas.
+// The draw function receives a cleared matrix the size of the widget's rectangle.
+func (b *base) render(draw func(m *bits.Matrix)) {
+	if b.canvas == nil || b.bounds.Height <= 0 || b.bounds.Width <= 0 {
+		return
+	}
+	h, w := b.canvas.Size()
+	if b.bounds.Row < 0 || b.bounds.Row >= h || b.bounds.Col < 0 || b.bounds.Col >= w {
+		return
+	}
+	m := bits.NewMatrix(b.bounds.Height, b.bounds.Width)
+	draw(m)
+	b.canvas.Write(m, b.bounds.Row, b.bounds.Col)
+}
+
+// Sets a rectangle of pixels in the matrix, clipping the rectangle to the extent of the matrix.
+func fill(m *bits.Matrix, row, col, height, width int) {
+	h, w := m.Size()
+	for i := row; i < row+height; i++ {
+		if i < 0 || i >= h {
+			continue
+		}
+		for j := col; j < col+width; j++ {
+			if j < 0 || j >= w {
+				continue
+			}
+			m.Set(i, j, true)
+		}
+	}
+}
+
+// Returns the number of pixels, out of n, representing a proportion of value between zero and max.
+func proportion(value, max float64, n int) int {
+	if max <= 0 || value <= 0 {
+		return 0
+	}
+	if value >= max {
+		return n
+	}
+	return int(value / max * float64(n))
+}
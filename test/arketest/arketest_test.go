@@ -0,0 +1,161 @@
+package arketest_test
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/realency/arke/pkg/arketest"
+	"github.com/realency/arke/pkg/bits"
+	"github.com/realency/arke/pkg/clock"
+	"github.com/realency/arke/pkg/display"
+	"github.com/realency/arke/pkg/transition"
+	"github.com/realency/arke/pkg/widget"
+)
+
+// The -update flag rewrites the golden files used by AssertGolden.
+var _ = flag.Bool("update", false, "update golden files in testdata with the actual frames")
+
+// recordingT records the errors reported through it, rather than failing the test.
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestMatrixReadsArt(t *testing.T) {
+	m := arketest.Matrix(`
+		#..#
+		.##
+	`)
+	if h, w := m.Size(); h != 2 || w != 4 {
+		t.Fatalf("Matrix is %dx%d, expected 2x4", h, w)
+	}
+	if !m.Get(0, 0) || !m.Get(0, 3) || !m.Get(1, 1) || m.Get(1, 3) {
+		t.Errorf("Unexpected matrix\n%v", m)
+	}
+	if art := arketest.Art(m); art != "#..#\n.##.\n" {
+		t.Errorf("Unexpected art %q", art)
+	}
+}
+
+func TestMatrixPanicsOnUnrecognisedCharacters(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("No panic")
+		}
+	}()
+	arketest.Matrix("#x#")
+}
+
+func TestAssertMatrixReportsDifferences(t *testing.T) {
+	rt := &recordingT{TB: t}
+	arketest.AssertMatrix(rt, arketest.Matrix("##."), arketest.Matrix("#.#"))
+	if len(rt.errors) != 1 {
+		t.Fatalf("Reported %d errors, expected 1", len(rt.errors))
+	}
+	if !strings.Contains(rt.errors[0], "##.    #.#    #+-") {
+		t.Errorf("Unexpected report\n%s", rt.errors[0])
+	}
+
+	rt = &recordingT{TB: t}
+	arketest.AssertMatrix(rt, arketest.Matrix("#.#"), arketest.Matrix("#.#"))
+	if len(rt.errors) != 0 {
+		t.Errorf("Equal matrices reported as different: %v", rt.errors)
+	}
+}
+
+func TestDiffReportsSizeMismatch(t *testing.T) {
+	d := arketest.Diff(arketest.Matrix("##"), arketest.Matrix("##\n##"))
+	if !strings.Contains(d, "got is 1x2, want is 2x2") {
+		t.Errorf("Unexpected diff\n%s", d)
+	}
+}
+
+func blockFont(r rune) *bits.Matrix {
+	return arketest.Matrix(`
+		##
+		#.
+		##
+	`)
+}
+
+func TestLabelMatchesGoldenFrame(t *testing.T) {
+	canvas := display.NewCanvas(5, 8)
+	widget.NewLabel(blockFont, "ab").Mount(canvas, widget.Rect{Row: 1, Col: 1, Height: 3, Width: 6})
+	arketest.AssertGolden(t, "label", canvas.Matrix())
+}
+
+func TestClockFiresWhenAdvanced(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := arketest.NewClock(start)
+	after := c.After(time.Second)
+
+	c.Advance(500 * time.Millisecond)
+	select {
+	case <-after:
+		t.Fatal("Fired early")
+	default:
+	}
+
+	c.Advance(500 * time.Millisecond)
+	select {
+	case now := <-after:
+		if !now.Equal(start.Add(time.Second)) {
+			t.Errorf("Fired at %v", now)
+		}
+	default:
+		t.Fatal("Did not fire")
+	}
+	if !c.Now().Equal(start.Add(time.Second)) {
+		t.Errorf("Clock at %v", c.Now())
+	}
+}
+
+func TestClockDrivesTransition(t *testing.T) {
+	fake := arketest.NewClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	canvas := display.NewCanvas(1, 4)
+	tr := transition.Start(canvas, 0, 0, arketest.Matrix("...."), arketest.Matrix("####"), transition.Wipe(transition.Right),
+		4*transition.FrameInterval, clock.With(fake))
+
+	// Each frame is rendered before the transition waits for the next
+	for _, want := range []string{"....", "#...", "##..", "###."} {
+		fake.BlockUntil(1)
+		arketest.AssertMatrix(t, canvas.Matrix(), arketest.Matrix(want))
+		fake.Advance(transition.FrameInterval)
+	}
+	if !tr.Wait() {
+		t.Fatal("Transition did not complete")
+	}
+	arketest.AssertMatrix(t, canvas.Matrix(), arketest.Matrix("####"))
+}
+
+func TestViewPortCapturesEveryFrame(t *testing.T) {
+	canvas := display.NewCanvas(2, 4)
+	vp := arketest.NewViewPort(2, 2)
+	defer vp.Close()
+
+	vp.Attach(canvas, 0, 0)
+	canvas.Set(0, 0, true)
+	canvas.Set(1, 3, true)
+	if err := vp.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	vp.Locate(0, 2)
+
+	frames := vp.Frames()
+	if len(frames) != 4 {
+		t.Fatalf("Captured %d frames, expected 4", len(frames))
+	}
+	arketest.AssertMatrix(t, frames[0], arketest.Matrix("..\n.."))
+	arketest.AssertMatrix(t, frames[1], arketest.Matrix("#.\n.."))
+	arketest.AssertMatrix(t, vp.Last(), arketest.Matrix("..\n.#"))
+}
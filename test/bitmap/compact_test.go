@@ -0,0 +1,211 @@
+package bitmap_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/realency/arke/pkg/arketest"
+	"github.com/realency/arke/pkg/bitmap"
+	"github.com/realency/arke/pkg/bits"
+)
+
+func TestPackBitsRoundTrips(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	inputs := [][]byte{
+		{},
+		{7},
+		{1, 1},
+		{1, 2, 3, 3, 3, 3, 4, 5, 5},
+		bytes.Repeat([]byte{0}, 300),
+	}
+	noisy := make([]byte, 500)
+	r.Read(noisy)
+	inputs = append(inputs, noisy)
+
+	for _, in := range inputs {
+		packed := bitmap.PackBits(nil, in)
+		out, err := bitmap.UnpackBits(nil, packed, len(in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(in, out) {
+			t.Errorf("Unpacked %v, expected %v", out, in)
+		}
+	}
+}
+
+func TestPackBitsMatchesReferenceEncoding(t *testing.T) {
+	in := []byte{0xAA, 0xAA, 0xAA, 0x80, 0x00, 0x2A, 0xAA, 0xAA, 0xAA, 0xAA, 0x80, 0x00, 0x2A, 0x22, 0xAA, 0xAA, 0xAA, 0xAA, 0xAA, 0xAA, 0xAA, 0xAA, 0xAA, 0xAA}
+	expected := []byte{0xFE, 0xAA, 0x02, 0x80, 0x00, 0x2A, 0xFD, 0xAA, 0x03, 0x80, 0x00, 0x2A, 0x22, 0xF7, 0xAA}
+	if packed := bitmap.PackBits(nil, in); !bytes.Equal(packed, expected) {
+		t.Errorf("Packed % X, expected % X", packed, expected)
+	}
+}
+
+func TestUnpackBitsRejectsInvalidData(t *testing.T) {
+	for _, data := range [][]byte{{0x02, 0x01}, {0xFE}, {0xFE, 0x01}} {
+		if _, err := bitmap.UnpackBits(nil, data, 2); !errors.Is(err, bitmap.ErrPackBits) {
+			t.Errorf("Unexpected error %v for % X", err, data)
+		}
+	}
+}
+
+var frames = []*bits.Matrix{
+	arketest.Matrix(`
+		.........#
+		.##....##.
+		..........
+	`),
+	arketest.Matrix(`
+		.........#
+		.##....##.
+		.....#....
+	`),
+	arketest.Matrix(`
+		##########
+		##########
+		##########
+	`),
+}
+
+func TestCompactStreamRoundTrips(t *testing.T) {
+	for _, delta := range []bool{true, false} {
+		var buf bytes.Buffer
+		e := bitmap.NewEncoder(&buf)
+		e.SetDelta(delta)
+		for _, m := range frames {
+			if err := e.Encode(m); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		d := bitmap.NewDecoder(&buf)
+		if h, w, err := d.Size(); err != nil || h != 3 || w != 10 {
+			t.Fatalf("Size %dx%d, %v", h, w, err)
+		}
+		for _, expected := range frames {
+			m, err := d.Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			arketest.AssertMatrix(t, m, expected)
+		}
+		if _, err := d.Decode(); err != io.EOF {
+			t.Errorf("Expected io.EOF, got %v", err)
+		}
+	}
+}
+
+func TestDeltaEncodingIsSmaller(t *testing.T) {
+	big := bits.NewMatrix(64, 128)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		big.Set(r.Intn(64), r.Intn(128), true)
+	}
+	next := big.Clone()
+	next.Set(10, 10, !next.Get(10, 10))
+
+	size := func(delta bool) int {
+		var buf bytes.Buffer
+		e := bitmap.NewEncoder(&buf)
+		e.SetDelta(delta)
+		e.Encode(big)
+		n := buf.Len()
+		e.Encode(next)
+		return buf.Len() - n
+	}
+	if with, without := size(true), size(false); with*10 > without {
+		t.Errorf("Second frame encoded in %d bytes with delta encoding, and %d without", with, without)
+	}
+}
+
+func TestRepeatedMatricesTakeNoData(t *testing.T) {
+	var buf bytes.Buffer
+	e := bitmap.NewEncoder(&buf)
+	e.Encode(frames[1])
+	n := buf.Len()
+	e.Encode(frames[1].Clone())
+	if size := buf.Len() - n; size != 2 {
+		t.Errorf("Repeated matrix encoded in %d bytes", size)
+	}
+
+	d := bitmap.NewDecoder(&buf)
+	for i := 0; i < 2; i++ {
+		m, err := d.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		arketest.AssertMatrix(t, m, frames[1])
+	}
+}
+
+func TestHeaderWithoutMatrices(t *testing.T) {
+	var buf bytes.Buffer
+	if err := bitmap.NewEncoder(&buf).WriteHeader(8, 32); err != nil {
+		t.Fatal(err)
+	}
+	d := bitmap.NewDecoder(&buf)
+	if h, w, err := d.Size(); err != nil || h != 8 || w != 32 {
+		t.Fatalf("Size %dx%d, %v", h, w, err)
+	}
+	if _, err := d.Decode(); err != io.EOF {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+
+	// A header claiming 65536x65536 matrices would need 512MB for each
+	huge := []byte("AKB1\x80\x80\x04\x80\x80\x04")
+	if _, _, err := bitmap.NewDecoder(bytes.NewReader(huge)).Size(); !errors.Is(err, bitmap.ErrCompact) {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestEncoderRejectsMismatchedSizes(t *testing.T) {
+	e := bitmap.NewEncoder(io.Discard)
+	e.Encode(bits.NewMatrix(2, 2))
+	if err := e.Encode(bits.NewMatrix(2, 3)); err == nil {
+		t.Error("Matrix of a different size accepted")
+	}
+}
+
+func TestEncoderRejectsSizesTheDecoderWould(t *testing.T) {
+	var buf bytes.Buffer
+	if err := bitmap.NewEncoder(&buf).WriteHeader(1<<16+1, 1); err == nil {
+		t.Error("Oversized header written")
+	}
+	if err := bitmap.NewEncoder(&buf).WriteHeader(1<<13, 1<<12); err == nil {
+		t.Error("Header with too many pixels written")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Wrote %d bytes", buf.Len())
+	}
+	if _, err := bitmap.Pack(bits.NewMatrix(1, 1<<16+1)); err == nil {
+		t.Error("Oversized matrix packed")
+	}
+}
+
+func TestPackAndUnpack(t *testing.T) {
+	solid := bits.NewMatrix(16, 64)
+	solid.Not()
+	if packed, err := bitmap.Pack(solid); err != nil || len(packed) > 12 {
+		t.Errorf("Solid matrix packed in %d bytes, error %v", len(packed), err)
+	}
+
+	packed, err := bitmap.Pack(frames[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := bitmap.Unpack(packed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	arketest.AssertMatrix(t, m, frames[2])
+
+	for _, bad := range [][]byte{nil, []byte("AKB1"), packed[:len(packed)-1], append([]byte("XXXX"), packed[4:]...)} {
+		if _, err := bitmap.Unpack(bad); !errors.Is(err, bitmap.ErrCompact) {
+			t.Errorf("Unexpected error %v for % X", err, bad)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package bits_test
+
+import (
+	"testing"
+
+	"github.com/realency/arke/pkg/bits"
+)
+
+// Returns a matrix in which every bit has been set and then cleared, so that only the padding bits beyond
+// the width of each row, and the spare trailing word, remain set in the underlying storage.
+func dirtyMatrix(s size) *bits.Matrix {
+	m := bits.NewMatrix(s.height, s.width)
+	m.Not()
+	for row := 0; row < s.height; row++ {
+		for col := 0; col < s.width; col++ {
+			m.Set(row, col, false)
+		}
+	}
+	return m
+}
+
+func TestEqualIgnoresPaddingBits(t *testing.T) {
+	for _, s := range []size{{1, 1}, {3, 31}, {3, 33}, {2, 64}, {5, 70}} {
+		clean := bits.NewMatrix(s.height, s.width)
+		dirty := dirtyMatrix(s)
+		if !clean.Equal(dirty) || !dirty.Equal(clean) {
+			t.Errorf("Blank %dx%d matrices not equal", s.height, s.width)
+		}
+		if clean.Hash() != dirty.Hash() {
+			t.Errorf("Blank %dx%d matrices have different hashes", s.height, s.width)
+		}
+		if n := dirty.PopCount(); n != 0 {
+			t.Errorf("Blank %dx%d matrix has population %d", s.height, s.width, n)
+		}
+		if _, _, h, w := dirty.BoundingBox(); h != 0 || w != 0 {
+			t.Errorf("Blank %dx%d matrix has bounding box %dx%d", s.height, s.width, h, w)
+		}
+	}
+}
+
+func TestEqualComparesSizeAndBits(t *testing.T) {
+	a := initMatrix(size{4, 40}, []coord{{0, 0}, {3, 39}})
+	b := initMatrix(size{4, 40}, []coord{{0, 0}, {3, 39}})
+	if !a.Equal(b) || a.Hash() != b.Hash() {
+		t.Error("Identical matrices not equal")
+	}
+
+	b.Set(2, 35, true)
+	if a.Equal(b) {
+		t.Error("Different matrices equal")
+	}
+	if a.Hash() == b.Hash() {
+		t.Error("Different matrices have the same hash")
+	}
+	if bits.NewMatrix(2, 8).Equal(bits.NewMatrix(8, 2)) {
+		t.Error("Matrices of different sizes equal")
+	}
+	if bits.NewMatrix(2, 8).Hash() == bits.NewMatrix(8, 2).Hash() {
+		t.Error("Matrices of different sizes have the same hash")
+	}
+}
+
+func TestPopCount(t *testing.T) {
+	m := bits.NewMatrix(10, 70)
+	m.Not()
+	if n := m.PopCount(); n != 700 {
+		t.Errorf("Population %d, expected 700", n)
+	}
+
+	m = initMatrix(size{10, 70}, []coord{{0, 0}, {1, 31}, {1, 32}, {5, 63}, {5, 64}, {9, 69}})
+	if n := m.PopCount(); n != 6 {
+		t.Errorf("Population %d, expected 6", n)
+	}
+
+	regions := []struct {
+		row, col, height, width int
+		expected                int
+	}{
+		{0, 0, 10, 70, 6},
+		{1, 31, 1, 2, 2},
+		{1, 32, 1, 1, 1},
+		{0, 1, 10, 62, 2},
+		{5, 63, 5, 100, 3},
+		{-5, -5, 6, 6, 1},
+		{20, 20, 5, 5, 0},
+	}
+	for _, r := range regions {
+		if n := m.PopCountRegion(r.row, r.col, r.height, r.width); n != r.expected {
+			t.Errorf("Population of region %v is %d, expected %d", r, n, r.expected)
+		}
+	}
+}
+
+func TestBoundingBoxAndTrim(t *testing.T) {
+	m := initMatrix(size{12, 80}, []coord{{3, 33}, {7, 40}, {5, 70}})
+	row, col, height, width := m.BoundingBox()
+	if row != 3 || col != 33 || height != 5 || width != 38 {
+		t.Errorf("Bounding box %d,%d %dx%d, expected 3,33 5x38", row, col, height, width)
+	}
+
+	trimmed := m.Trim()
+	expected := initMatrix(size{5, 38}, []coord{{0, 0}, {4, 7}, {2, 37}})
+	if !trimmed.Equal(expected) {
+		t.Errorf("Trimmed matrix is\n%v", trimmed)
+	}
+
+	if h, w := bits.NewMatrix(4, 4).Trim().Size(); h != 0 || w != 0 {
+		t.Errorf("Blank matrix trimmed to %dx%d", h, w)
+	}
+}
@@ -178,3 +178,22 @@ func TestCloneIsNotReferenceIdenticalToSource(t *testing.T) {
 		t.Error("Clone matrix affected by change to source matrix")
 	}
 }
+
+func TestCopyPreservesBitsAcrossUnalignedWords(t *testing.T) {
+	for sourceCol := 0; sourceCol < 40; sourceCol++ {
+		for destCol := 0; destCol < 40; destCol++ {
+			source := bits.NewMatrix(1, 120)
+			for col := sourceCol; col < 120; col += 3 {
+				source.Set(0, col, true)
+			}
+			dest := bits.NewMatrix(1, 120)
+			bits.Copy(source, 0, sourceCol, dest, 0, destCol, 1, 70)
+
+			for i := 0; i < 70; i++ {
+				if dest.Get(0, destCol+i) != source.Get(0, sourceCol+i) {
+					t.Fatalf("Bit %d differs after copying from column %d to column %d", i, sourceCol, destCol)
+				}
+			}
+		}
+	}
+}
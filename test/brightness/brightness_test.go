@@ -0,0 +1,180 @@
+package brightness_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/realency/arke/pkg/arketest"
+	"github.com/realency/arke/pkg/brightness"
+	"github.com/realency/arke/pkg/clock"
+	"periph.io/x/conn/v3/i2c/i2ctest"
+)
+
+// recordingDisplay records each brightness level set.
+type recordingDisplay struct {
+	mutex  sync.Mutex
+	levels []byte
+}
+
+func (d *recordingDisplay) SetBrightness(bright byte) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.levels = append(d.levels, bright)
+}
+
+func (d *recordingDisplay) recorded() []byte {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return append([]byte(nil), d.levels...)
+}
+
+type fakeSensor struct {
+	lux float64
+	err error
+}
+
+func (s *fakeSensor) Lux() (float64, error) {
+	return s.lux, s.err
+}
+
+func TestFadeToStepsThroughEachLevel(t *testing.T) {
+	d := &recordingDisplay{}
+	dimmer := brightness.NewDimmer(d, 2)
+
+	<-dimmer.FadeTo(6, 20*time.Millisecond)
+
+	expected := []byte{2, 3, 4, 5, 6}
+	if actual := d.recorded(); string(actual) != string(expected) {
+		t.Errorf("Levels set were %v, expected %v", actual, expected)
+	}
+	if dimmer.Level() != 6 {
+		t.Errorf("Level is %d, expected 6", dimmer.Level())
+	}
+}
+
+func TestCrossFadeStartsAtFromLevel(t *testing.T) {
+	d := &recordingDisplay{}
+	dimmer := brightness.NewDimmer(d, 0)
+
+	<-dimmer.CrossFade(12, 10, 10*time.Millisecond)
+
+	expected := []byte{0, 12, 11, 10}
+	if actual := d.recorded(); string(actual) != string(expected) {
+		t.Errorf("Levels set were %v, expected %v", actual, expected)
+	}
+}
+
+func TestSetCancelsBreathing(t *testing.T) {
+	d := &recordingDisplay{}
+	fake := arketest.NewClock(time.Now())
+	dimmer := brightness.NewDimmer(d, 8, clock.With(fake))
+
+	// Four steps down to the low level, and one back up
+	done := dimmer.Breathe(4, 8, 16*time.Millisecond)
+	for i := 0; i < 5; i++ {
+		fake.BlockUntil(1)
+		fake.Advance(2 * time.Millisecond)
+	}
+	fake.BlockUntil(1)
+	dimmer.Set(15)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Breathing not cancelled")
+	}
+	seen := make(map[byte]bool)
+	for _, l := range d.recorded() {
+		seen[l] = true
+	}
+	if !seen[4] || !seen[5] {
+		t.Errorf("Breathing did not reach low level: %v", d.recorded())
+	}
+	fake.Advance(time.Second)
+	if dimmer.Level() != 15 {
+		t.Errorf("Level is %d after Set, expected 15", dimmer.Level())
+	}
+}
+
+func TestBreatheRejectsNonPositivePeriod(t *testing.T) {
+	dimmer := brightness.NewDimmer(&recordingDisplay{}, 8)
+	defer func() {
+		if recover() == nil {
+			t.Error("Breathe accepted a zero period")
+		}
+	}()
+	dimmer.Breathe(4, 8, 0)
+}
+
+func TestAutoAppliesHysteresis(t *testing.T) {
+	sensor := &fakeSensor{}
+	dimmer := brightness.NewDimmer(&recordingDisplay{}, 0)
+	auto := brightness.NewAuto(sensor, dimmer)
+	auto.SetThresholds([]float64{10, 100})
+	auto.SetHysteresis(0.2)
+	auto.SetFadeTime(0)
+
+	steps := []struct {
+		lux   float64
+		level byte
+	}{
+		{5, 0},
+		{11, 0},
+		{13, 1},
+		{9, 1},
+		{8, 0},
+		{150, 2},
+		{90, 2},
+		{70, 1},
+	}
+
+	for _, s := range steps {
+		sensor.lux = s.lux
+		if err := auto.Sample(); err != nil {
+			t.Fatal(err)
+		}
+		if dimmer.Level() != s.level {
+			t.Errorf("Level %d at %v lux, expected %d", dimmer.Level(), s.lux, s.level)
+		}
+	}
+}
+
+func TestAutoLeavesLevelOnSensorError(t *testing.T) {
+	sensor := &fakeSensor{err: errors.New("no sensor")}
+	dimmer := brightness.NewDimmer(&recordingDisplay{}, 7)
+	auto := brightness.NewAuto(sensor, dimmer)
+
+	if err := auto.Sample(); err == nil {
+		t.Error("Sensor error not returned")
+	}
+	if dimmer.Level() != 7 {
+		t.Errorf("Level is %d, expected 7", dimmer.Level())
+	}
+}
+
+func TestBH1750ConvertsReadingToLux(t *testing.T) {
+	bus := &i2ctest.Playback{
+		Ops: []i2ctest.IO{
+			{Addr: brightness.BH1750AddrLow, W: []byte{0x01}},
+			{Addr: brightness.BH1750AddrLow, W: []byte{0x10}},
+			{Addr: brightness.BH1750AddrLow, R: []byte{0x01, 0x2C}},
+		},
+	}
+
+	sensor, err := brightness.NewBH1750(bus, brightness.BH1750AddrLow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lux, err := sensor.Lux()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lux != 250 {
+		t.Errorf("Read %v lux, expected 250", lux)
+	}
+	if err := bus.Close(); err != nil {
+		t.Error(err)
+	}
+}
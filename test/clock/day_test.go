@@ -0,0 +1,34 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/realency/arke/pkg/clock"
+)
+
+func TestTimeOfDayIsOffsetFromMidnight(t *testing.T) {
+	tod := clock.TimeOfDay(time.Date(2024, time.March, 1, 13, 45, 30, 500, time.UTC))
+	if expected := 13*time.Hour + 45*time.Minute + 30*time.Second + 500; tod != expected {
+		t.Errorf("Time of day is %v, expected %v", tod, expected)
+	}
+}
+
+func TestBetweenWrapsAtMidnight(t *testing.T) {
+	cases := []struct {
+		tod, from, to time.Duration
+		expected      bool
+	}{
+		{12 * time.Hour, 8 * time.Hour, 18 * time.Hour, true},
+		{18 * time.Hour, 8 * time.Hour, 18 * time.Hour, false},
+		{23 * time.Hour, 22 * time.Hour, 6 * time.Hour, true},
+		{3 * time.Hour, 22 * time.Hour, 6 * time.Hour, true},
+		{12 * time.Hour, 22 * time.Hour, 6 * time.Hour, false},
+		{8 * time.Hour, 8 * time.Hour, 8 * time.Hour, false},
+	}
+	for _, c := range cases {
+		if actual := clock.Between(c.tod, c.from, c.to); actual != c.expected {
+			t.Errorf("Between(%v, %v, %v) is %v, expected %v", c.tod, c.from, c.to, actual, c.expected)
+		}
+	}
+}
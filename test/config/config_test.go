@@ -0,0 +1,196 @@
+package config_test
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/realency/arke/pkg/config"
+	"github.com/realency/arke/pkg/max7219"
+	"github.com/realency/arke/pkg/viewport"
+)
+
+type fakeBus struct {
+	mutex sync.Mutex
+	sent  int
+}
+
+func (b *fakeBus) Add(reg max7219.Register, data byte) {}
+
+func (b *fakeBus) Send() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.sent++
+}
+
+const site = `{
+	"buses": [{"name": "spi0", "device": "SPI0.0"}],
+	"displays": [
+		{"name": "left", "type": "max7219", "bus": "spi0", "chain": 4, "blockOrientation": "bottom", "chainOrientation": "right", "brightness": 3},
+		{"name": "right", "type": "terminal", "height": 8, "width": 32},
+		{"name": "sign", "type": "composite", "panels": [{"display": "left"}, {"display": "right", "col": 32}]}
+	],
+	"canvases": [{"name": "main", "height": 8, "width": 128, "viewports": [{"display": "sign", "col": 8}]}]
+}`
+
+func TestBuildAttachesViewPortsToCanvases(t *testing.T) {
+	cfg, err := config.Load(strings.NewReader(site))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bus := &fakeBus{}
+	sys, err := cfg.BuildWith(map[string]max7219.Bus{"spi0": bus})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := sys.ViewPorts["sign"]
+	if h, w := sign.Size(); h != 8 || w != 64 {
+		t.Errorf("Composite size is %dx%d, expected 8x64", h, w)
+	}
+	if sign.Canvas() != sys.Canvases["main"] {
+		t.Error("Composite not attached to canvas")
+	}
+	if row, col := sys.ViewPorts["right"].Offset(); row != 0 || col != 40 {
+		t.Errorf("Terminal panel at %d,%d, expected 0,40", row, col)
+	}
+}
+
+func TestValidationErrorsIdentifyFields(t *testing.T) {
+	bad := strings.Replace(site, `"chain": 4`, `"chain": 0`, 1)
+	bad = strings.Replace(bad, `{"display": "right", "col": 32}`, `{"display": "missing"}`, 1)
+
+	_, err := config.Load(strings.NewReader(bad))
+	var verr config.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected a ValidationError, got %v", err)
+	}
+
+	fields := make(map[string]bool)
+	for _, f := range verr {
+		fields[f.Field] = true
+	}
+	for _, expected := range []string{"displays[0].chain", "displays[2].panels[1].display"} {
+		if !fields[expected] {
+			t.Errorf("No error reported for %s in %v", expected, err)
+		}
+	}
+}
+
+func TestDisplayMayBeUsedOnlyOnce(t *testing.T) {
+	bad := strings.Replace(site, `[{"display": "sign", "col": 8}]`, `[{"display": "sign"}, {"display": "left"}]`, 1)
+	_, err := config.Load(strings.NewReader(bad))
+	if err == nil || !strings.Contains(err.Error(), "canvases[0].viewports[1].display") {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+// closableBus is a fakeBus that records whether it has been closed.
+type closableBus struct {
+	fakeBus
+	closed bool
+}
+
+func (b *closableBus) Close() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.closed = true
+	return nil
+}
+
+func TestFailedBuildLeavesGivenBusesOpen(t *testing.T) {
+	cfg, err := config.Load(strings.NewReader(`{
+		"buses": [{"name": "spi0", "device": "SPI0.0"}, {"name": "spi1", "device": "NO_SUCH_DEVICE"}],
+		"displays": [{"name": "left", "type": "max7219", "bus": "spi0", "chain": 4, "blockOrientation": "bottom", "chainOrientation": "right"}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bus := &closableBus{}
+	if _, err := cfg.BuildWith(map[string]max7219.Bus{"spi0": bus}); err == nil {
+		t.Fatal("Build succeeded with unknown device")
+	}
+	if bus.closed {
+		t.Error("Given bus closed when build failed")
+	}
+}
+
+func TestBusMayDriveOnlyOneDisplay(t *testing.T) {
+	bad := strings.Replace(site, `{"name": "right", "type": "terminal", "height": 8, "width": 32}`,
+		`{"name": "right", "type": "max7219", "bus": "spi0", "chain": 4, "blockOrientation": "bottom", "chainOrientation": "right"}`, 1)
+	_, err := config.Load(strings.NewReader(bad))
+	if err == nil || !strings.Contains(err.Error(), "displays[1].bus") {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestBusParametersAreValidated(t *testing.T) {
+	bad := strings.Replace(site, `"device": "SPI0.0"`, `"device": "SPI0", "clockSpeed": "fast", "mode": 4, "chipSelect": -1`, 1)
+	_, err := config.Load(strings.NewReader(bad))
+	for _, expected := range []string{"buses[0].clockSpeed", "buses[0].mode", "buses[0].chipSelect"} {
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("No error reported for %s in %v", expected, err)
+		}
+	}
+
+	good := strings.Replace(site, `"device": "SPI0.0"`, `"device": "SPI0", "clockSpeed": "4MHz", "mode": 0, "chipSelect": 1`, 1)
+	if _, err := config.Load(strings.NewReader(good)); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestBusPinsExcludeDevice(t *testing.T) {
+	bad := strings.Replace(site, `"device": "SPI0.0"`, `"device": "SPI0.0", "pins": {"din": "GPIO10", "clk": "GPIO11"}`, 1)
+	_, err := config.Load(strings.NewReader(bad))
+	if err == nil || !strings.Contains(err.Error(), "buses[0].pins") {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestDisplaysMayBeTransformed(t *testing.T) {
+	bad := strings.Replace(site, `"brightness": 3`, `"brightness": 3, "rotate": 45`, 1)
+	if _, err := config.Load(strings.NewReader(bad)); err == nil || !strings.Contains(err.Error(), "displays[0].rotate") {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	good := strings.Replace(site, `"brightness": 3`, `"brightness": 3, "rotate": 180, "invert": true`, 1)
+	cfg, err := config.Load(strings.NewReader(good))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sys, err := cfg.BuildWith(map[string]max7219.Bus{"spi0": &fakeBus{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sys.ViewPorts["left"].(*viewport.Transformed); !ok {
+		t.Errorf("Display is %T, expected a transformed viewport", sys.ViewPorts["left"])
+	}
+	if h, w := sys.ViewPorts["sign"].Size(); h != 8 || w != 64 {
+		t.Errorf("Composite size is %dx%d, expected 8x64", h, w)
+	}
+}
+
+func TestCloseClosesViewPortsAndTheirBuses(t *testing.T) {
+	cfg, err := config.Load(strings.NewReader(site))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bus := &closableBus{}
+	sys, err := cfg.BuildWith(map[string]max7219.Bus{"spi0": bus})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sys.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if sys.ViewPorts["sign"].Canvas() != nil {
+		t.Error("Composite still attached after closing")
+	}
+	if !bus.closed {
+		t.Error("Bus not closed with its viewport")
+	}
+}
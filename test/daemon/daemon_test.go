@@ -0,0 +1,151 @@
+package daemon_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/realency/arke/pkg/daemon"
+	"github.com/realency/arke/pkg/display"
+)
+
+func TestLeasedRegionsMayNotOverlap(t *testing.T) {
+	d := daemon.New(display.NewCanvas(8, 32), nil)
+	defer d.Close()
+
+	resp := d.Execute(daemon.Request{Op: "lease", Region: "metrics", Height: 8, Width: 16})
+	if !resp.OK || resp.Token == "" {
+		t.Fatalf("Lease failed: %s", resp.Error)
+	}
+
+	resp = d.Execute(daemon.Request{Op: "lease", Region: "alerts", Col: 8, Height: 8, Width: 16})
+	if resp.OK {
+		t.Error("Overlapping lease succeeded")
+	}
+
+	resp = d.Execute(daemon.Request{Op: "lease", Region: "alerts", Col: 16, Height: 8, Width: 16})
+	if !resp.OK {
+		t.Errorf("Adjacent lease failed: %s", resp.Error)
+	}
+}
+
+func TestDrawingRequiresLeaseToken(t *testing.T) {
+	canvas := display.NewCanvas(8, 32)
+	d := daemon.New(canvas, nil)
+	defer d.Close()
+
+	token := d.Execute(daemon.Request{Op: "lease", Region: "r", Col: 8, Height: 8, Width: 8}).Token
+
+	if resp := d.Execute(daemon.Request{Op: "draw", Region: "r", Token: "wrong", Pixels: []string{"#"}}); resp.OK {
+		t.Error("Draw succeeded with wrong token")
+	}
+
+	resp := d.Execute(daemon.Request{Op: "draw", Region: "r", Token: token, Row: 1, Pixels: []string{".#", "#."}})
+	if !resp.OK {
+		t.Fatalf("Draw failed: %s", resp.Error)
+	}
+	if !canvas.Get(1, 9) || !canvas.Get(2, 8) || canvas.Get(1, 8) {
+		t.Error("Pixels not drawn at expected location")
+	}
+
+	d.Execute(daemon.Request{Op: "release", Region: "r", Token: token})
+	if canvas.Get(1, 9) {
+		t.Error("Region not cleared on release")
+	}
+}
+
+func TestSocketCarriesLineDelimitedJSON(t *testing.T) {
+	d := daemon.New(display.NewCanvas(8, 32), nil)
+	defer d.Close()
+
+	path := filepath.Join(t.TempDir(), "arked.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("Unix sockets unavailable: %v", err)
+	}
+	defer l.Close()
+	go d.Serve(l)
+
+	cx, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cx.Close()
+
+	cx.Write([]byte(`{"op":"lease","region":"clock","height":8,"width":32}` + "\n" + `{"op":"regions"}` + "\n"))
+	scanner := bufio.NewScanner(cx)
+	var resps []daemon.Response
+	for len(resps) < 2 && scanner.Scan() {
+		var r daemon.Response
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatal(err)
+		}
+		resps = append(resps, r)
+	}
+
+	if len(resps) != 2 || !resps[0].OK || len(resps[1].Regions) != 1 || resps[1].Regions[0].Name != "clock" {
+		t.Errorf("Unexpected responses %+v", resps)
+	}
+}
+
+func TestHTTPReportsConflict(t *testing.T) {
+	d := daemon.New(display.NewCanvas(8, 32), nil)
+	defer d.Close()
+	server := httptest.NewServer(d)
+	defer server.Close()
+
+	body := `{"region":"a","height":8,"width":8}`
+	for i, expected := range []int{http.StatusOK, http.StatusConflict} {
+		resp, err := http.Post(server.URL+"/api/lease", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != expected {
+			t.Errorf("Request %d returned status %d, expected %d", i, resp.StatusCode, expected)
+		}
+	}
+}
+
+// dimmableViewPort is a ViewPort that records the brightness it was last set to.
+type dimmableViewPort struct {
+	canvas *display.Canvas
+	bright byte
+}
+
+func (v *dimmableViewPort) Attach(canvas *display.Canvas, row, col int) { v.canvas = canvas }
+func (v *dimmableViewPort) Detach()                                     { v.canvas = nil }
+func (v *dimmableViewPort) Locate(row, col int)                         {}
+func (v *dimmableViewPort) Offset() (row, col int)                      { return 0, 0 }
+func (v *dimmableViewPort) Size() (height, width int)                   { return 8, 32 }
+func (v *dimmableViewPort) Canvas() *display.Canvas                     { return v.canvas }
+func (v *dimmableViewPort) SetBrightness(bright byte)                   { v.bright = bright }
+
+func TestBrightnessRequiresLeaseToken(t *testing.T) {
+	vp := &dimmableViewPort{}
+	d := daemon.New(display.NewCanvas(8, 32), vp)
+	defer d.Close()
+
+	if resp := d.Execute(daemon.Request{Op: "brightness", Level: 7}); resp.OK {
+		t.Error("Brightness succeeded without a lease")
+	}
+	token := d.Execute(daemon.Request{Op: "lease", Region: "r", Height: 8, Width: 8}).Token
+	if resp := d.Execute(daemon.Request{Op: "brightness", Region: "r", Token: "wrong", Level: 7}); resp.OK {
+		t.Error("Brightness succeeded with wrong token")
+	}
+	if vp.bright != 0 {
+		t.Fatalf("Brightness set to %d without authorisation", vp.bright)
+	}
+
+	if resp := d.Execute(daemon.Request{Op: "brightness", Region: "r", Token: token, Level: 7}); !resp.OK {
+		t.Fatalf("Brightness failed: %s", resp.Error)
+	}
+	if vp.bright != 7 {
+		t.Errorf("Brightness is %d, expected 7", vp.bright)
+	}
+}
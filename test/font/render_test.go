@@ -0,0 +1,26 @@
+package font_test
+
+import (
+	"testing"
+
+	"github.com/realency/arke/pkg/bits"
+	"github.com/realency/arke/pkg/font"
+)
+
+func TestRenderLaysGlyphsSideBySide(t *testing.T) {
+	glyph := func(r rune) *bits.Matrix {
+		m := bits.NewMatrix(int(r-'0'), 2)
+		m.Set(0, 0, true)
+		return m
+	}
+
+	line := font.Render(glyph, "132")
+	if h, w := line.Size(); h != 3 || w != 6 {
+		t.Fatalf("Line is %dx%d, expected 3x6", h, w)
+	}
+	for col := 0; col < 6; col++ {
+		if line.Get(0, col) != (col%2 == 0) {
+			t.Errorf("Pixel 0,%d is %v", col, line.Get(0, col))
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package max7219_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/realency/arke/pkg/max7219"
+	"periph.io/x/conn/v3/spi/spitest"
+)
+
+func TestBuildRejectsChipSelectForDeviceWithChipSelect(t *testing.T) {
+	_, err := max7219.FromDeviceName("SPI0.0").WithChipSelect(1).Build()
+	if err == nil || !strings.Contains(err.Error(), `"SPI0.0"`) {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestBuildReportsPortThatCannotBeOpened(t *testing.T) {
+	_, err := max7219.FromDeviceName("NOSUCHSPI").WithChipSelect(3).Build()
+	if err == nil || !strings.Contains(err.Error(), `"NOSUCHSPI.3"`) {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestBuildRejectsChipSelectForPreCreatedPort(t *testing.T) {
+	_, err := max7219.FromSpiPort(&spitest.Record{}).WithChipSelect(1).Build()
+	if err == nil || !strings.Contains(err.Error(), "chip select 1") {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	if _, err := max7219.FromSpiPort(&spitest.Record{}).Build(); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestBuildRejectsEmptyChain(t *testing.T) {
+	_, err := max7219.FromSpiPort(&spitest.Record{}).WithChainLength(0).Build()
+	if err == nil || !strings.Contains(err.Error(), "chain length 0") {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package max7219_test
+
+import (
+	"sync"
+
+	"github.com/realency/arke/pkg/max7219"
+)
+
+type write struct {
+	reg  max7219.Register
+	data byte
+}
+
+// recordingBus records each packet sent.
+type recordingBus struct {
+	mutex   sync.Mutex
+	pending []write
+	packets [][]write
+}
+
+func (b *recordingBus) Add(reg max7219.Register, data byte) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.pending = append(b.pending, write{reg, data})
+}
+
+func (b *recordingBus) Send() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.packets = append(b.packets, b.pending)
+	b.pending = nil
+}
+
+func (b *recordingBus) sent() [][]write {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return append([][]write(nil), b.packets...)
+}
+
+// Returns the packets sent that write to a given register.
+func (b *recordingBus) writesTo(reg max7219.Register) [][]write {
+	var result [][]write
+	for _, p := range b.sent() {
+		for _, w := range p {
+			if w.reg == reg {
+				result = append(result, p)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// Returns the state of the registers of each chip in a chain, after applying every packet sent.
+func (b *recordingBus) registers(chainLength int) []map[max7219.Register]byte {
+	result := make([]map[max7219.Register]byte, chainLength)
+	for i := range result {
+		result[i] = make(map[max7219.Register]byte)
+	}
+	for _, p := range b.sent() {
+		for i, w := range p {
+			if w.reg != max7219.NoOpRegister {
+				result[i][w.reg] = w.data
+			}
+		}
+	}
+	return result
+}
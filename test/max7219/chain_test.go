@@ -0,0 +1,68 @@
+package max7219_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/realency/arke/pkg/max7219"
+)
+
+func TestChainWriteSendsNoOpToOtherChips(t *testing.T) {
+	bus := &recordingBus{}
+	chain := max7219.NewChain(bus, 4)
+
+	chain.SetIntensity(2, 9)
+
+	packets := bus.sent()
+	if len(packets) != 1 {
+		t.Fatalf("Sent %d packets, expected 1", len(packets))
+	}
+	expected := []write{
+		{max7219.NoOpRegister, 0},
+		{max7219.NoOpRegister, 0},
+		{max7219.IntensityRegister, 9},
+		{max7219.NoOpRegister, 0},
+	}
+	for i, w := range packets[0] {
+		if w != expected[i] {
+			t.Errorf("Pair %d was %v, expected %v", i, w, expected[i])
+		}
+	}
+}
+
+func TestChainWritePanicsForChipOutOfRange(t *testing.T) {
+	chain := max7219.NewChain(&recordingBus{}, 4)
+	for _, chip := range []int{-1, 4} {
+		func() {
+			defer func() {
+				recover()
+			}()
+			chain.SetShutdown(chip, true)
+			t.Errorf("Did not panic for chip %d", chip)
+		}()
+	}
+}
+
+func TestViewPortSetsShutdownForSingleChip(t *testing.T) {
+	bus := &recordingBus{}
+	vp, err := max7219.FromBus(bus).WithChainLength(3).WithOrientation(max7219.DigitZeroAtBottom, max7219.BlockZeroAtRight).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	initial := len(bus.writesTo(max7219.ShutdownRegister))
+
+	vp.SetChipShutdown(1, true)
+
+	deadline := time.Now().Add(time.Second)
+	for len(bus.writesTo(max7219.ShutdownRegister)) == initial && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	packets := bus.writesTo(max7219.ShutdownRegister)
+	if len(packets) != initial+1 {
+		t.Fatalf("Sent %d shutdown packets, expected %d", len(packets), initial+1)
+	}
+	last := packets[len(packets)-1]
+	if last[0].reg != max7219.NoOpRegister || last[1] != (write{max7219.ShutdownRegister, max7219.Shutdown}) || last[2].reg != max7219.NoOpRegister {
+		t.Errorf("Unexpected packet %v", last)
+	}
+}
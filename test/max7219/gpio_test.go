@@ -0,0 +1,147 @@
+package max7219_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/realency/arke/pkg/max7219"
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/gpio/gpiotest"
+)
+
+// shiftRegister records the bits clocked in on DIN, and the frames latched on each rising edge of LOAD.
+type shiftRegister struct {
+	mutex   sync.Mutex
+	din     *gpiotest.Pin
+	bits    []bool
+	frames  [][]byte
+	lowLoad bool
+}
+
+type clockPin struct {
+	*gpiotest.Pin
+	r *shiftRegister
+}
+
+func (p *clockPin) Out(l gpio.Level) error {
+	rising := l == gpio.High && p.Read() == gpio.Low
+	p.Pin.Out(l)
+	if rising {
+		p.r.mutex.Lock()
+		defer p.r.mutex.Unlock()
+		p.r.bits = append(p.r.bits, bool(p.r.din.Read()))
+	}
+	return nil
+}
+
+type loadPin struct {
+	*gpiotest.Pin
+	r *shiftRegister
+}
+
+func (p *loadPin) Out(l gpio.Level) error {
+	rising := l == gpio.High && p.Read() == gpio.Low
+	p.Pin.Out(l)
+	p.r.mutex.Lock()
+	defer p.r.mutex.Unlock()
+	if l == gpio.Low {
+		p.r.lowLoad = true
+	}
+	if rising && p.r.lowLoad {
+		frame := make([]byte, len(p.r.bits)/8)
+		for i, b := range p.r.bits {
+			if b {
+				frame[i/8] |= 0x80 >> (i % 8)
+			}
+		}
+		p.r.frames = append(p.r.frames, frame)
+		p.r.bits = nil
+	}
+	return nil
+}
+
+func (r *shiftRegister) latched() [][]byte {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.frames
+}
+
+func TestPinBusShiftsFramesAndLatchesOnLoad(t *testing.T) {
+	r := &shiftRegister{din: &gpiotest.Pin{N: "DIN"}}
+	clk := &clockPin{&gpiotest.Pin{N: "CLK"}, r}
+	load := &loadPin{&gpiotest.Pin{N: "LOAD"}, r}
+
+	bus, err := max7219.FromPins(r.din, clk, load).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bus.Add(max7219.IntensityRegister, 0x05)
+	bus.Add(max7219.Digit3Register, 0xA5)
+	bus.Send()
+
+	deadline := time.Now().Add(time.Second)
+	for len(r.latched()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	frames := r.latched()
+	if len(frames) != 1 {
+		t.Fatalf("Latched %d frames, expected 1", len(frames))
+	}
+	expected := []byte{byte(max7219.IntensityRegister), 0x05, byte(max7219.Digit3Register), 0xA5}
+	if string(frames[0]) != string(expected) {
+		t.Errorf("Latched % x, expected % x", frames[0], expected)
+	}
+	if load.Read() != gpio.High {
+		t.Error("LOAD left low after transaction")
+	}
+}
+
+// failingPin is a pin whose writes fail once failing is set.
+type failingPin struct {
+	*gpiotest.Pin
+	mutex   sync.Mutex
+	failing bool
+}
+
+func (p *failingPin) Out(l gpio.Level) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.failing {
+		return errors.New("pin failed")
+	}
+	return p.Pin.Out(l)
+}
+
+func TestPinBusReportsFailedWrites(t *testing.T) {
+	clk := &failingPin{Pin: &gpiotest.Pin{N: "CLK"}}
+	bus, err := max7219.FromPins(&gpiotest.Pin{N: "DIN"}, clk, &gpiotest.Pin{N: "LOAD"}).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clk.mutex.Lock()
+	clk.failing = true
+	clk.mutex.Unlock()
+	bus.Add(max7219.IntensityRegister, 0x05)
+	bus.Send()
+
+	if err := max7219.FlushBus(context.Background(), bus); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.(interface{ Err() error }).Err(); err == nil || !strings.Contains(err.Error(), "CLK") {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestFromPinNamesReportsUnknownPin(t *testing.T) {
+	_, err := max7219.FromPinNames("NOSUCHPIN", "GPIO11", "GPIO8").Build()
+	if err == nil {
+		t.Error("Expected an error for an unknown pin")
+	}
+}
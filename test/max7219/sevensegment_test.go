@@ -0,0 +1,111 @@
+package max7219_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/realency/arke/pkg/max7219"
+	"periph.io/x/conn/v3/spi/spitest"
+)
+
+func newSevenSegment(t *testing.T, chainLength, digits int) (*max7219.SevenSegment, *recordingBus) {
+	bus := &recordingBus{}
+	s, err := max7219.FromBus(bus).WithDigits(chainLength, digits).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, bus
+}
+
+// Returns the values of the digit registers of a display, from the leftmost digit to the rightmost.
+func digits(bus *recordingBus, chainLength, digitsPerChip int) []byte {
+	regs := bus.registers(chainLength)
+	result := make([]byte, chainLength*digitsPerChip)
+	for p := range result {
+		result[len(result)-1-p] = regs[p/digitsPerChip][max7219.DigitRegister(p%digitsPerChip)]
+	}
+	return result
+}
+
+func TestSevenSegmentSetsScanLimitFromDigitCount(t *testing.T) {
+	_, bus := newSevenSegment(t, 2, 6)
+	for chip, regs := range bus.registers(2) {
+		if regs[max7219.ScanLimitRegister] != 5 {
+			t.Errorf("Chip %d has scan limit %d, expected 5", chip, regs[max7219.ScanLimitRegister])
+		}
+	}
+}
+
+func TestSevenSegmentRightAlignsNumbersAcrossChips(t *testing.T) {
+	s, bus := newSevenSegment(t, 2, 4)
+	s.SetFloat(-123.45, 2)
+
+	expected := []byte{
+		max7219.CharBlank, max7219.CharBlank, max7219.CharDash, max7219.Char1,
+		max7219.Char2, max7219.Char3 | max7219.DecimalPoint, max7219.Char4, max7219.Char5,
+	}
+	if actual := digits(bus, 2, 4); string(actual) != string(expected) {
+		t.Errorf("Digits are % x, expected % x", actual, expected)
+	}
+	for chip, regs := range bus.registers(2) {
+		if regs[max7219.DecodeModeRegister] != 0x0F {
+			t.Errorf("Chip %d has decode mode %#02x, expected 0x0f", chip, regs[max7219.DecodeModeRegister])
+		}
+	}
+}
+
+func TestSevenSegmentUsesRawSegmentsForCharactersCodeBLacks(t *testing.T) {
+	s, bus := newSevenSegment(t, 1, 4)
+	s.SetAlignment(max7219.AlignLeft)
+	fmt.Fprintf(s, "A1\n")
+
+	a, _ := max7219.Segments('A')
+	expected := []byte{a, max7219.Char1, max7219.CharBlank, max7219.CharBlank}
+	if actual := digits(bus, 1, 4); string(actual) != string(expected) {
+		t.Errorf("Digits are % x, expected % x", actual, expected)
+	}
+	if mode := bus.registers(1)[0][max7219.DecodeModeRegister]; mode != 0x07 {
+		t.Errorf("Decode mode is %#02x, expected 0x07", mode)
+	}
+}
+
+func TestSevenSegmentShowsDashesForNumberTooWide(t *testing.T) {
+	s, bus := newSevenSegment(t, 1, 4)
+	s.SetInt(12345)
+
+	dash := max7219.CharDash
+	expected := []byte{dash, dash, dash, dash}
+	if actual := digits(bus, 1, 4); string(actual) != string(expected) {
+		t.Errorf("Digits are % x, expected % x", actual, expected)
+	}
+}
+
+func TestSevenSegmentSetsDecimalPointIndependentlyOfText(t *testing.T) {
+	s, bus := newSevenSegment(t, 1, 4)
+	s.SetMode(max7219.RawSegments)
+	s.SetText("12")
+	s.SetDecimalPoint(3, true)
+
+	one, _ := max7219.Segments('1')
+	two, _ := max7219.Segments('2')
+	expected := []byte{max7219.DecimalPoint, 0, one, two}
+	if actual := digits(bus, 1, 4); string(actual) != string(expected) {
+		t.Errorf("Digits are % x, expected % x", actual, expected)
+	}
+	if mode := bus.registers(1)[0][max7219.DecodeModeRegister]; mode != max7219.DecodeNone {
+		t.Errorf("Decode mode is %#02x, expected none", mode)
+	}
+}
+
+func TestSevenSegmentMayBeClosedTwice(t *testing.T) {
+	s, err := max7219.FromSpiPort(&spitest.Record{}).WithDigits(1, 8).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("Second close returned %v", err)
+	}
+}
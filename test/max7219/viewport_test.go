@@ -0,0 +1,204 @@
+package max7219_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/realency/arke/pkg/display"
+	"github.com/realency/arke/pkg/max7219"
+)
+
+func eventually(condition func() bool) bool {
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return true
+}
+
+func TestViewPortTracksCanvasWhileAsleep(t *testing.T) {
+	bus := &recordingBus{}
+	vp, err := max7219.FromBus(bus).WithChainLength(1).WithOrientation(max7219.DigitZeroAtBottom, max7219.BlockZeroAtRight).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	canvas := display.NewCanvas(8, 8)
+	vp.Attach(canvas, 0, 0)
+
+	vp.Sleep()
+	if !vp.Asleep() {
+		t.Error("Asleep false after Sleep")
+	}
+	canvas.Set(7, 0, true)
+
+	asleep := func() bool {
+		return bus.registers(1)[0][max7219.ShutdownRegister] == max7219.Shutdown
+	}
+	if !eventually(asleep) {
+		t.Fatal("Display not shut down")
+	}
+	if !eventually(func() bool { return bus.registers(1)[0][max7219.Digit0Register] != 0 }) {
+		t.Error("Digit register not updated while asleep")
+	}
+
+	vp.Wake()
+	if !eventually(func() bool { return !asleep() }) {
+		t.Error("Display not woken")
+	}
+}
+
+func TestCloseBlanksDisplayAndDetaches(t *testing.T) {
+	bus := &recordingBus{}
+	vp, err := max7219.FromBus(bus).WithChainLength(2).WithOrientation(max7219.DigitZeroAtBottom, max7219.BlockZeroAtRight).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vp.Attach(display.NewCanvas(8, 16), 0, 0)
+
+	if err := vp.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for chip, regs := range bus.registers(2) {
+		if regs[max7219.ShutdownRegister] != max7219.Shutdown {
+			t.Errorf("Chip %d not shut down", chip)
+		}
+	}
+	if vp.Canvas() != nil {
+		t.Error("ViewPort still attached after Close")
+	}
+}
+
+func TestFlushAppliesQueuedOperations(t *testing.T) {
+	bus := &recordingBus{}
+	vp, err := max7219.FromBus(bus).WithChainLength(1).WithOrientation(max7219.DigitZeroAtBottom, max7219.BlockZeroAtRight).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	canvas := display.NewCanvas(8, 16)
+	vp.Attach(canvas, 0, 20)
+	vp.SetBrightness(9)
+	canvas.Set(7, 15, true)
+
+	if err := vp.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if row, col := vp.Offset(); row != 0 || col != 8 {
+		t.Errorf("Offset is %d,%d, expected 0,8", row, col)
+	}
+	regs := bus.registers(1)[0]
+	if regs[max7219.IntensityRegister] != 9 {
+		t.Error("Brightness not applied by Flush")
+	}
+	if regs[max7219.Digit0Register] == 0 {
+		t.Error("Canvas update not applied by Flush")
+	}
+}
+
+func TestOnFrameReportsEachFrame(t *testing.T) {
+	vp, err := max7219.FromBus(&recordingBus{}).WithChainLength(1).WithOrientation(max7219.DigitZeroAtBottom, max7219.BlockZeroAtRight).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mutex sync.Mutex
+	var frames []max7219.FrameInfo
+	vp.OnFrame(func(f max7219.FrameInfo) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		frames = append(frames, f)
+	})
+
+	canvas := display.NewCanvas(8, 8)
+	vp.Attach(canvas, 0, 0)
+	vp.Flush(context.Background())
+	canvas.Set(0, 0, true)
+	vp.Flush(context.Background())
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(frames) != 2 {
+		t.Fatalf("Reported %d frames, expected 2", len(frames))
+	}
+	if frames[1].Sequence != frames[0].Sequence+1 || frames[1].Sent.Before(frames[1].Started) {
+		t.Errorf("Unexpected frames %+v", frames)
+	}
+}
+
+func TestOperationsAreAppliedInOrder(t *testing.T) {
+	bus := &recordingBus{}
+	vp, err := max7219.FromBus(bus).WithChainLength(1).WithOrientation(max7219.DigitZeroAtBottom, max7219.BlockZeroAtRight).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vp.Close()
+	canvas := display.NewCanvas(16, 16)
+
+	for i := 0; i < 100; i++ {
+		vp.Detach()
+		vp.Attach(canvas, 0, 0)
+		vp.Locate(0, 8)
+		vp.SetBrightness(byte(i % 16))
+		vp.SetBrightness(3)
+	}
+	if err := vp.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if row, col := vp.Offset(); row != 0 || col != 8 {
+		t.Errorf("Offset is %d,%d, expected the location requested last, 0,8", row, col)
+	}
+	if b := bus.registers(1)[0][max7219.IntensityRegister]; b != 3 {
+		t.Errorf("Brightness is %d, expected the brightness requested last, 3", b)
+	}
+}
+
+func TestClosedViewPortIgnoresFurtherRequests(t *testing.T) {
+	vp, err := max7219.FromBus(&recordingBus{}).WithChainLength(1).WithOrientation(max7219.DigitZeroAtBottom, max7219.BlockZeroAtRight).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vp.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			vp.SetBrightness(byte(i % 16))
+		}
+		vp.Flush(context.Background())
+		vp.Close()
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Requests blocked after Close")
+	}
+}
+
+func TestReattachingDropsUpdatesOfPreviousCanvas(t *testing.T) {
+	vp, err := max7219.FromBus(&recordingBus{}).WithChainLength(1).WithOrientation(max7219.DigitZeroAtBottom, max7219.BlockZeroAtRight).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vp.Close()
+	wide := display.NewCanvas(8, 64)
+
+	// Updates of the narrow canvas drawn at the offset into the wide one would fall outside the narrow canvas
+	for i := 0; i < 100; i++ {
+		narrow := display.NewCanvas(8, 8)
+		vp.Attach(narrow, 0, 0)
+		vp.Flush(context.Background())
+		for j := 0; j < 8; j++ {
+			narrow.Set(j, j, true)
+		}
+		vp.Attach(wide, 0, 56)
+	}
+	if err := vp.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,193 @@
+package mqtt_test
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/realency/arke/pkg/arketest"
+	"github.com/realency/arke/pkg/display"
+	"github.com/realency/arke/pkg/mqtt"
+	"github.com/realency/arke/pkg/mqtt/mqtttest"
+	"github.com/realency/arke/pkg/viewport"
+)
+
+// dimmableViewPort is a fake ViewPort that also records its brightness.
+// The controller drives it from the client's goroutine, so its brightness is guarded by a mutex.
+type dimmableViewPort struct {
+	*arketest.ViewPort
+	mutex      sync.Mutex
+	brightness byte
+}
+
+func newDimmableViewPort() *dimmableViewPort {
+	return &dimmableViewPort{ViewPort: arketest.NewViewPort(8, 32)}
+}
+
+func (d *dimmableViewPort) SetBrightness(bright byte) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.brightness = bright
+}
+
+// plainViewPort hides the optional capabilities of the ViewPort it wraps.
+type plainViewPort struct {
+	viewport.ViewPort
+}
+
+func startBroker(t *testing.T) (*mqtttest.Broker, string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := mqtttest.NewBroker()
+	go b.Serve(l)
+	t.Cleanup(b.Close)
+	return b, l.Addr().String()
+}
+
+func startClient(t *testing.T, addr, id string) *mqtt.Client {
+	c := mqtt.NewClient(mqtt.Options{Broker: addr, ClientID: id, ReconnectDelay: 10 * time.Millisecond})
+	c.Start()
+	t.Cleanup(c.Close)
+	select {
+	case <-c.Connected():
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Client %s did not connect", id)
+	}
+	return c
+}
+
+func receive(t *testing.T, ch <-chan []byte) []byte {
+	t.Helper()
+	select {
+	case b := <-ch:
+		return b
+	case <-time.After(2 * time.Second):
+		t.Fatal("No message received")
+		return nil
+	}
+}
+
+func TestControllerAppliesTextAndPublishesState(t *testing.T) {
+	_, addr := startBroker(t)
+
+	canvas := display.NewCanvas(8, 32)
+	vp := newDimmableViewPort()
+	vp.Attach(canvas, 0, 0)
+	sign := mqtt.NewClient(mqtt.Options{Broker: addr, ClientID: "sign"})
+	c := mqtt.NewController(sign, "home/sign", canvas, vp)
+	sign.Start()
+	defer sign.Close()
+	<-sign.Connected()
+
+	states := make(chan []byte, 10)
+	remote := startClient(t, addr, "remote")
+	remote.Subscribe("home/sign/state", func(topic string, payload []byte) { states <- payload })
+
+	// The first state received is the retained state published on connection
+	receive(t, states)
+
+	remote.Publish("home/sign/text", []byte("Hi"), false)
+	var s mqtt.State
+	if err := json.Unmarshal(receive(t, states), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Text != "Hi" || c.State().Text != "Hi" {
+		t.Errorf("Unexpected state %+v", s)
+	}
+	if canvas.Matrix().String() == display.NewCanvas(8, 32).Matrix().String() {
+		t.Error("Text not drawn on canvas")
+	}
+
+	remote.Publish("home/sign/power", []byte("off"), false)
+	receive(t, states)
+	if vp.Canvas() == canvas {
+		t.Error("Viewport still showing canvas after power off")
+	}
+}
+
+func TestControllerReportsErrors(t *testing.T) {
+	_, addr := startBroker(t)
+
+	canvas := display.NewCanvas(8, 32)
+	sign := mqtt.NewClient(mqtt.Options{Broker: addr, ClientID: "sign"})
+	mqtt.NewController(sign, "sign", canvas, newDimmableViewPort())
+	sign.Start()
+	defer sign.Close()
+	<-sign.Connected()
+
+	errs := make(chan []byte, 10)
+	remote := startClient(t, addr, "remote")
+	remote.Subscribe("sign/error", func(topic string, payload []byte) { errs <- payload })
+	time.Sleep(50 * time.Millisecond)
+
+	remote.Publish("sign/brightness", []byte("99"), false)
+	receive(t, errs)
+}
+
+func TestControllerBlanksCompositeWhoseChildrenCannotSleep(t *testing.T) {
+	_, addr := startBroker(t)
+
+	canvas := display.NewCanvas(8, 32)
+	child := arketest.NewViewPort(8, 32)
+	vp := viewport.NewComposite()
+	vp.Add(plainViewPort{child}, 0, 0)
+	vp.Attach(canvas, 0, 0)
+	sign := mqtt.NewClient(mqtt.Options{Broker: addr, ClientID: "sign"})
+	mqtt.NewController(sign, "sign", canvas, vp)
+	sign.Start()
+	defer sign.Close()
+	<-sign.Connected()
+
+	states := make(chan []byte, 10)
+	errs := make(chan []byte, 10)
+	remote := startClient(t, addr, "remote")
+	remote.Subscribe("sign/state", func(topic string, payload []byte) { states <- payload })
+	remote.Subscribe("sign/error", func(topic string, payload []byte) { errs <- payload })
+	receive(t, states)
+
+	remote.Publish("sign/power", []byte("off"), false)
+	receive(t, states)
+	if child.Canvas() == canvas {
+		t.Error("Child still showing canvas after power off")
+	}
+
+	remote.Publish("sign/brightness", []byte("5"), false)
+	if e := string(receive(t, errs)); !strings.Contains(e, "does not support brightness") {
+		t.Errorf("Unexpected error %q", e)
+	}
+}
+
+func TestClientResubscribesAfterReconnecting(t *testing.T) {
+	broker, addr := startBroker(t)
+
+	received := make(chan []byte, 10)
+	sub := startClient(t, addr, "sub")
+	sub.Subscribe("a/+", func(topic string, payload []byte) { received <- payload })
+	pub := startClient(t, addr, "pub")
+	time.Sleep(50 * time.Millisecond)
+
+	broker.DisconnectAll()
+
+	// Publishing is retried, since the clients may not yet have noticed the lost connection
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(3 * time.Second)
+	for {
+		pub.Publish("a/b", []byte("x"), false)
+		select {
+		case payload := <-received:
+			if string(payload) != "x" {
+				t.Error("Unexpected payload")
+			}
+			return
+		case <-ticker.C:
+		case <-timeout:
+			t.Fatal("No message received after reconnecting")
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package playlist_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/realency/arke/pkg/arketest"
+	"github.com/realency/arke/pkg/clock"
+	"github.com/realency/arke/pkg/playlist"
+	"github.com/realency/arke/pkg/viewport"
+	"github.com/realency/arke/pkg/widget"
+)
+
+// recorder is content that records each time it starts playing.
+type recorder struct {
+	name  string
+	plays chan<- string
+}
+
+func (r *recorder) Play(vp viewport.ViewPort, stop <-chan struct{}) {
+	r.plays <- r.name
+	<-stop
+}
+
+func expectPlays(t *testing.T, plays <-chan string, expected ...string) {
+	t.Helper()
+	for _, e := range expected {
+		select {
+		case p := <-plays:
+			if p != e {
+				t.Fatalf("Played %q, expected %q", p, e)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timed out waiting for %q", e)
+		}
+	}
+}
+
+func TestSchedulerPlaysItemsInOrderWithRepeats(t *testing.T) {
+	plays := make(chan string, 20)
+	s := playlist.NewScheduler(arketest.NewViewPort(8, 32))
+	s.SetPlaylist(&playlist.Playlist{Items: []playlist.Item{
+		{Content: &recorder{"a", plays}, Duration: 10 * time.Millisecond, Repeat: 2},
+		{Content: &recorder{"b", plays}, Duration: 10 * time.Millisecond},
+		{Content: &recorder{"never", plays}, Duration: 10 * time.Millisecond, Window: &playlist.Window{Days: []time.Weekday{(time.Now().Weekday() + 3) % 7}}},
+	}})
+	s.Start()
+	defer s.Stop()
+
+	expectPlays(t, plays, "a", "a", "b", "a", "a", "b")
+}
+
+func TestInterruptPreemptsAndResumes(t *testing.T) {
+	plays := make(chan string, 20)
+	s := playlist.NewScheduler(arketest.NewViewPort(8, 32))
+	s.SetPlaylist(&playlist.Playlist{Items: []playlist.Item{
+		{Content: &recorder{"main", plays}, Duration: time.Hour},
+	}})
+	s.Start()
+	defer s.Stop()
+
+	expectPlays(t, plays, "main")
+	s.Interrupt(playlist.Item{Content: &recorder{"alert", plays}, Duration: 10 * time.Millisecond}, 1)
+	expectPlays(t, plays, "alert", "main")
+}
+
+func TestSchedulerTimesItemsByItsClock(t *testing.T) {
+	plays := make(chan string, 20)
+	fake := arketest.NewClock(time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC))
+	s := playlist.NewScheduler(arketest.NewViewPort(8, 32), clock.With(fake))
+	s.SetPlaylist(&playlist.Playlist{Items: []playlist.Item{
+		{Content: &recorder{"day", plays}, Duration: time.Minute, Window: &playlist.Window{From: 8 * time.Hour, To: 18 * time.Hour}},
+		{Content: &recorder{"night", plays}, Duration: time.Minute, Window: &playlist.Window{From: 22 * time.Hour, To: 6 * time.Hour}},
+	}})
+	s.Start()
+	defer s.Stop()
+
+	expectPlays(t, plays, "day")
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	expectPlays(t, plays, "day")
+	fake.BlockUntil(1)
+	fake.Advance(10 * time.Hour)
+	expectPlays(t, plays, "night")
+}
+
+func TestWindowSpanningMidnight(t *testing.T) {
+	w := &playlist.Window{From: 22 * time.Hour, To: 6 * time.Hour, Days: []time.Weekday{time.Friday}}
+
+	friday := time.Date(2021, 10, 1, 23, 0, 0, 0, time.UTC)
+	saturday := time.Date(2021, 10, 2, 3, 0, 0, 0, time.UTC)
+	saturdayNoon := time.Date(2021, 10, 2, 12, 0, 0, 0, time.UTC)
+
+	if !w.Contains(friday) || !w.Contains(saturday) {
+		t.Error("Window does not contain times on the night starting Friday")
+	}
+	if w.Contains(saturdayNoon) {
+		t.Error("Window contains a time outside its hours")
+	}
+}
+
+func TestLoadReportsOffendingItem(t *testing.T) {
+	_, err := playlist.Load(strings.NewReader(`{"items":[{"type":"text","text":"hi"},{"type":"bogus"}]}`), ".", nil)
+	if err == nil || !strings.Contains(err.Error(), "items[1]") {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	p, err := playlist.Load(strings.NewReader(`{"items":[{"type":"clock","duration":"1m","window":{"from":"08:00","to":"18:00","days":["mon","fri"]}}]}`), ".", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := p.Items[0].Window; w == nil || w.From != 8*time.Hour || len(w.Days) != 2 {
+		t.Errorf("Unexpected window %+v", w)
+	}
+}
+
+func TestLoadFindsNamedWidgets(t *testing.T) {
+	bar := widget.NewProgressBar()
+	widgets := map[string]widget.Widget{"load": bar}
+
+	p, err := playlist.Load(strings.NewReader(`{"items":[{"type":"widget","widget":"load","duration":"5s"}]}`), ".", widgets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, ok := p.Items[0].Content.(*playlist.Widget); !ok || c.Widget != bar {
+		t.Errorf("Unexpected content %#v", p.Items[0].Content)
+	}
+
+	_, err = playlist.Load(strings.NewReader(`{"items":[{"type":"widget","widget":"unknown"}]}`), ".", widgets)
+	if err == nil || !strings.Contains(err.Error(), "items[0]: widget") {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
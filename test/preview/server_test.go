@@ -0,0 +1,82 @@
+package preview_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/realency/arke/pkg/display"
+	"github.com/realency/arke/pkg/preview"
+)
+
+type frame struct {
+	Height int      `json:"height"`
+	Width  int      `json:"width"`
+	Rows   []string `json:"rows"`
+}
+
+func TestFrameEncodesCanvasRows(t *testing.T) {
+	canvas := display.NewCanvas(2, 12)
+	canvas.Set(0, 0, true)
+	canvas.Set(1, 11, true)
+	s := preview.NewServer(canvas)
+	defer s.Close()
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest("GET", "/frame", nil))
+
+	var f frame
+	if err := json.Unmarshal(rec.Body.Bytes(), &f); err != nil {
+		t.Fatal(err)
+	}
+	if f.Height != 2 || f.Width != 12 {
+		t.Errorf("Frame size is %dx%d, expected 2x12", f.Height, f.Width)
+	}
+	if len(f.Rows) != 2 || f.Rows[0] != "8000" || f.Rows[1] != "0010" {
+		t.Errorf("Unexpected rows %v", f.Rows)
+	}
+}
+
+func TestEventsStreamCanvasUpdates(t *testing.T) {
+	canvas := display.NewCanvas(1, 8)
+	s := preview.NewServer(canvas)
+	defer s.Close()
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	canvas.Set(0, 7, true)
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatal("Event stream ended")
+			}
+			if strings.HasPrefix(line, "data: ") && strings.Contains(line, `"01"`) {
+				return
+			}
+		case <-timeout:
+			t.Fatal("Canvas update not streamed")
+		}
+	}
+}
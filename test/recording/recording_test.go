@@ -0,0 +1,280 @@
+package recording_test
+
+import (
+	"bytes"
+	"errors"
+	"image/gif"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/realency/arke/pkg/bits"
+	"github.com/realency/arke/pkg/display"
+	"github.com/realency/arke/pkg/recording"
+)
+
+func frame(t time.Duration, height, width int, lit ...[2]int) recording.Frame {
+	m := bits.NewMatrix(height, width)
+	for _, p := range lit {
+		m.Set(p[0], p[1], true)
+	}
+	return recording.Frame{Time: t, Matrix: m}
+}
+
+func sample() *recording.Recording {
+	return &recording.Recording{
+		Height: 3,
+		Width:  5,
+		Frames: []recording.Frame{
+			frame(0, 3, 5),
+			frame(40*time.Millisecond, 3, 5, [2]int{0, 0}, [2]int{2, 4}),
+			frame(80*time.Millisecond, 3, 5, [2]int{0, 0}, [2]int{2, 4}),
+			frame(500*time.Millisecond, 3, 5, [2]int{1, 1}, [2]int{1, 2}, [2]int{1, 3}),
+		},
+	}
+}
+
+func TestRecordingRoundTrips(t *testing.T) {
+	rec := sample()
+	var buf bytes.Buffer
+	if err := rec.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := recording.Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Height != 3 || loaded.Width != 5 || len(loaded.Frames) != len(rec.Frames) {
+		t.Fatalf("Loaded %dx%d recording with %d frames", loaded.Height, loaded.Width, len(loaded.Frames))
+	}
+	for i, f := range loaded.Frames {
+		if f.Time != rec.Frames[i].Time {
+			t.Errorf("Frame %d at %v, expected %v", i, f.Time, rec.Frames[i].Time)
+		}
+		if f.Matrix.String() != rec.Frames[i].Matrix.String() {
+			t.Errorf("Frame %d is\n%v\nexpected\n%v", i, f.Matrix, rec.Frames[i].Matrix)
+		}
+	}
+}
+
+func TestEmptyRecordingRoundTrips(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&recording.Recording{Height: 8, Width: 32}).Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := recording.Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Height != 8 || loaded.Width != 32 || len(loaded.Frames) != 0 {
+		t.Errorf("Loaded %dx%d recording with %d frames", loaded.Height, loaded.Width, len(loaded.Frames))
+	}
+}
+
+func TestFrameTimesDoNotDrift(t *testing.T) {
+	rec := &recording.Recording{Height: 1, Width: 1}
+	for i := 0; i < 1000; i++ {
+		rec.Frames = append(rec.Frames, frame(time.Duration(i)*1500*time.Nanosecond, 1, 1))
+	}
+	var buf bytes.Buffer
+	if err := rec.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := recording.Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, expected := loaded.Duration(), rec.Duration().Truncate(time.Microsecond); d != expected {
+		t.Errorf("Duration is %v, expected %v", d, expected)
+	}
+}
+
+func TestUnchangedFramesAreCompact(t *testing.T) {
+	var buf bytes.Buffer
+	e := recording.NewEncoder(&buf, 64, 128)
+	if err := e.Encode(frame(0, 64, 128)); err != nil {
+		t.Fatal(err)
+	}
+	first := buf.Len()
+	for i := 1; i < 10; i++ {
+		if err := e.Encode(frame(time.Duration(i)*time.Millisecond, 64, 128)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := buf.Len() - first; n > 9*4 {
+		t.Errorf("Nine unchanged frames encoded in %d bytes", n)
+	}
+}
+
+func TestEncoderRejectsMismatchedFrames(t *testing.T) {
+	e := recording.NewEncoder(&bytes.Buffer{}, 3, 5)
+	if err := e.Encode(frame(0, 4, 5)); err == nil {
+		t.Error("Frame of the wrong size accepted")
+	}
+	if err := e.Encode(frame(time.Second, 3, 5)); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Encode(frame(0, 3, 5)); err == nil {
+		t.Error("Frame out of order accepted")
+	}
+}
+
+func TestDecoderRejectsInvalidInput(t *testing.T) {
+	if _, err := recording.Load(bytes.NewReader([]byte("GIF89a"))); !errors.Is(err, recording.ErrFormat) {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	// A header claiming 65536x65536 frames would need 512MB for each
+	huge := []byte("ARKR\x02AKB1\x80\x80\x04\x80\x80\x04")
+	if _, err := recording.Load(bytes.NewReader(huge)); !errors.Is(err, recording.ErrFormat) {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	var buf bytes.Buffer
+	sample().Save(&buf)
+	truncated := buf.Bytes()[:buf.Len()-1]
+	if _, err := recording.Load(bytes.NewReader(truncated)); !errors.Is(err, recording.ErrFormat) {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestRecorderRecordsCanvasFrames(t *testing.T) {
+	canvas := display.NewCanvas(4, 4)
+	canvas.Set(0, 0, true)
+
+	var buf bytes.Buffer
+	r := recording.Record(canvas, &buf)
+	canvas.Set(1, 1, true)
+	canvas.Set(2, 2, true)
+	if err := r.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	canvas.Set(3, 3, true)
+
+	rec, err := recording.Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.Frames) != 3 {
+		t.Fatalf("Recorded %d frames, expected 3", len(rec.Frames))
+	}
+	if !rec.Frames[0].Matrix.Get(0, 0) || rec.Frames[0].Matrix.Get(1, 1) {
+		t.Error("First frame is not the content of the canvas when recording started")
+	}
+	if last := rec.Frames[2].Matrix; !last.Get(2, 2) || last.Get(3, 3) {
+		t.Error("Last frame is not the content of the canvas when recording stopped")
+	}
+}
+
+// gatedWriter is a writer whose writes wait, once it has been closed, until its gate is opened.
+type gatedWriter struct {
+	bytes.Buffer
+	mutex sync.Mutex
+	gate  chan struct{}
+}
+
+func (w *gatedWriter) close() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.gate = make(chan struct{})
+}
+
+func (w *gatedWriter) open() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	close(w.gate)
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	gate := w.gate
+	w.mutex.Unlock()
+	if gate != nil {
+		<-gate
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestRecorderCountsFramesDroppedBySlowWriter(t *testing.T) {
+	canvas := display.NewCanvas(4, 4)
+	w := &gatedWriter{}
+	r := recording.Record(canvas, w)
+	w.close()
+
+	deadline := time.Now().Add(time.Second)
+	for i := 0; r.Dropped() == 0; i++ {
+		if time.Now().After(deadline) {
+			t.Fatal("No frames dropped while the writer was blocked")
+		}
+		canvas.Set(0, i%4, i%2 == 0)
+		runtime.Gosched()
+	}
+	w.open()
+	if err := r.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	rec, err := recording.Load(&w.Buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(rec.Frames); i++ {
+		if rec.Frames[i].Time < rec.Frames[i-1].Time {
+			t.Fatalf("Frame %d recorded before the frame preceding it", i)
+		}
+	}
+}
+
+func TestPlayerReplaysRecording(t *testing.T) {
+	rec := sample()
+	canvas := display.NewCanvas(3, 5)
+	updates := make(chan *bits.Matrix, 10)
+	canvas.AddObserver(updates)
+
+	p := recording.Play(canvas, rec, 100)
+	if !p.Wait() {
+		t.Fatal("Replay did not complete")
+	}
+	if len(updates) != len(rec.Frames) {
+		t.Errorf("Replayed %d frames, expected %d", len(updates), len(rec.Frames))
+	}
+	if canvas.Matrix().String() != rec.Frames[3].Matrix.String() {
+		t.Error("Canvas does not show the last frame")
+	}
+}
+
+func TestPlayerMayBeCancelled(t *testing.T) {
+	canvas := display.NewCanvas(3, 5)
+	p := recording.Play(canvas, sample(), 0.01)
+	p.Cancel()
+	if p.Wait() {
+		t.Error("Cancelled replay reported as complete")
+	}
+}
+
+func TestWriteGIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := recording.WriteGIF(&buf, sample(), 2); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Image) != 4 {
+		t.Fatalf("GIF has %d frames, expected 4", len(g.Image))
+	}
+	if g.Delay[0] != 4 || g.Delay[2] != 42 || g.Delay[3] != 100 {
+		t.Errorf("Unexpected delays %v", g.Delay)
+	}
+	img := g.Image[1]
+	if b := img.Bounds(); b.Dx() != 10 || b.Dy() != 6 {
+		t.Fatalf("GIF is %dx%d, expected 10x6", b.Dx(), b.Dy())
+	}
+	if img.ColorIndexAt(1, 1) != 1 || img.ColorIndexAt(9, 5) != 1 || img.ColorIndexAt(2, 0) != 0 {
+		t.Error("Pixels not drawn as scaled squares")
+	}
+}
@@ -0,0 +1,156 @@
+package terminal_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/realency/arke/pkg/display"
+	"github.com/realency/arke/pkg/terminal"
+	"github.com/realency/arke/pkg/viewport"
+)
+
+func TestBuildFailsWithoutSize(t *testing.T) {
+	if _, err := terminal.ToWriter(&bytes.Buffer{}).Build(); err == nil {
+		t.Error("Build succeeded without a size")
+	}
+}
+
+func TestHalfBlocksDrawsTwoRowsPerLine(t *testing.T) {
+	var out bytes.Buffer
+	vp, err := terminal.ToWriter(&out).WithSize(2, 3).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	canvas := display.NewCanvas(2, 3)
+	canvas.Set(0, 0, true)
+	canvas.Set(1, 1, true)
+	canvas.Set(0, 2, true)
+	canvas.Set(1, 2, true)
+
+	var v viewport.ViewPort = vp
+	v.Attach(canvas, 0, 0)
+
+	if !strings.Contains(out.String(), "▀▄█") {
+		t.Errorf("Unexpected output %q", out.String())
+	}
+}
+
+func TestBrailleDrawsEightPixelsPerCharacter(t *testing.T) {
+	var out bytes.Buffer
+	vp, err := terminal.ToWriter(&out).WithSize(4, 2).WithStyle(terminal.Braille).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	canvas := display.NewCanvas(4, 2)
+	canvas.Set(0, 0, true)
+	canvas.Set(3, 1, true)
+	vp.Attach(canvas, 0, 0)
+
+	if !strings.Contains(out.String(), "⢁") {
+		t.Errorf("Unexpected output %q", out.String())
+	}
+}
+
+func TestLocateRedrawsInPlace(t *testing.T) {
+	var out bytes.Buffer
+	vp, err := terminal.ToWriter(&out).WithSize(4, 4).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vp.Attach(display.NewCanvas(8, 8), 0, 0)
+	vp.Locate(4, 4)
+
+	if row, col := vp.Offset(); row != 4 || col != 4 {
+		t.Errorf("ViewPort located at %d,%d, expected 4,4", row, col)
+	}
+	if !strings.Contains(out.String(), "\x1b[2A") {
+		t.Errorf("Second frame not drawn over first: %q", out.String())
+	}
+}
+
+// lockedBuffer is a buffer that may be written by the goroutine of a ViewPort while read by a test.
+type lockedBuffer struct {
+	mutex sync.Mutex
+	buff  bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buff.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buff.String()
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("terminal gone")
+}
+
+func TestSleepBlanksAndWakeRedraws(t *testing.T) {
+	var out bytes.Buffer
+	vp, err := terminal.ToWriter(&out).WithSize(2, 1).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	canvas := display.NewCanvas(2, 1)
+	canvas.Set(0, 0, true)
+	vp.Attach(canvas, 0, 0)
+
+	out.Reset()
+	viewport.Sleep(vp)
+	if !vp.Asleep() || strings.Contains(out.String(), "▀") {
+		t.Errorf("Display not blanked: %q", out.String())
+	}
+
+	out.Reset()
+	viewport.Wake(vp)
+	if !strings.Contains(out.String(), "▀") {
+		t.Errorf("Display not redrawn: %q", out.String())
+	}
+}
+
+func TestErrReportsWriteFailure(t *testing.T) {
+	vp, err := terminal.ToWriter(failingWriter{}).WithSize(2, 1).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vp.Attach(display.NewCanvas(2, 1), 0, 0)
+
+	if viewport.Err(vp) == nil {
+		t.Error("Write failure not reported")
+	}
+	if err := viewport.Close(vp); err == nil || vp.Canvas() != nil {
+		t.Errorf("Close did not detach and report the failure: %v", err)
+	}
+}
+
+func TestFlushWaitsForCanvasUpdates(t *testing.T) {
+	out := &lockedBuffer{}
+	vp, err := terminal.ToWriter(out).WithSize(2, 1).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	canvas := display.NewCanvas(2, 1)
+	vp.Attach(canvas, 0, 0)
+	canvas.Set(1, 0, true)
+
+	if err := vp.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "▄") {
+		t.Errorf("Update not drawn by Flush: %q", out.String())
+	}
+}
@@ -0,0 +1,116 @@
+package transition_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/realency/arke/pkg/bits"
+	"github.com/realency/arke/pkg/display"
+	"github.com/realency/arke/pkg/transition"
+)
+
+func filled(height, width int) *bits.Matrix {
+	m := bits.NewMatrix(height, width)
+	m.Not()
+	return m
+}
+
+func countOnes(m *bits.Matrix) int {
+	h, w := m.Size()
+	n := 0
+	for i := 0; i < h; i++ {
+		for j := 0; j < w; j++ {
+			if m.Get(i, j) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestEffectsStartOnFromFrameAndFinishOnToFrame(t *testing.T) {
+	effects := map[string]transition.Effect{
+		"SlideIn":  transition.SlideIn(transition.Left),
+		"SlideOut": transition.SlideOut(transition.Down),
+		"Push":     transition.Push(transition.Right),
+		"ScrollUp": transition.ScrollUp(),
+		"Wipe":     transition.Wipe(transition.Up),
+		"Dissolve": transition.Dissolve(42),
+		"Blinds":   transition.Blinds(3),
+	}
+
+	from := bits.NewMatrix(8, 16)
+	to := filled(8, 16)
+
+	for name, effect := range effects {
+		dest := bits.NewMatrix(8, 16)
+
+		effect(dest, from, to, 0)
+		if n := countOnes(dest); n != 0 {
+			t.Errorf("%s: first frame has %d one-bits, expected 0", name, n)
+		}
+
+		effect(dest, from, to, 1)
+		if n := countOnes(dest); n != 8*16 {
+			t.Errorf("%s: last frame has %d one-bits, expected %d", name, n, 8*16)
+		}
+	}
+}
+
+func TestSlideInMovesFrameFromOppositeEdge(t *testing.T) {
+	dest := bits.NewMatrix(8, 16)
+	transition.SlideIn(transition.Left)(dest, bits.NewMatrix(8, 16), filled(8, 16), 0.25)
+
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 16; j++ {
+			if expected := j >= 12; dest.Get(i, j) != expected {
+				t.Errorf("Value dest[%d,%d] was %v, when %v was expected.", i, j, dest.Get(i, j), expected)
+			}
+		}
+	}
+}
+
+func TestDissolveSwitchesPixelsProgressively(t *testing.T) {
+	dest := bits.NewMatrix(10, 10)
+	effect := transition.Dissolve(7)
+	effect(dest, bits.NewMatrix(10, 10), filled(10, 10), 0.5)
+	if n := countOnes(dest); n != 50 {
+		t.Errorf("Half-way frame has %d one-bits, expected 50", n)
+	}
+}
+
+func TestDissolveMayBeSharedByConcurrentTransitions(t *testing.T) {
+	effect := transition.Dissolve(7)
+	a := transition.Start(display.NewCanvas(8, 8), 0, 0, bits.NewMatrix(8, 8), filled(8, 8), effect, 50*time.Millisecond)
+	b := transition.Start(display.NewCanvas(4, 16), 0, 0, bits.NewMatrix(4, 16), filled(4, 16), effect, 50*time.Millisecond)
+	if !a.Wait() || !b.Wait() {
+		t.Fatal("Transition reported as cancelled")
+	}
+}
+
+func TestStartCompletesWithFinishingFrameOnCanvas(t *testing.T) {
+	canvas := display.NewCanvas(8, 8)
+	tr := transition.Start(canvas, 0, 0, bits.NewMatrix(8, 8), filled(8, 8), transition.Wipe(transition.Right), 100*time.Millisecond)
+
+	if !tr.Wait() {
+		t.Fatal("Transition reported as cancelled")
+	}
+	if n := countOnes(canvas.Matrix()); n != 64 {
+		t.Errorf("Canvas has %d one-bits after transition, expected 64", n)
+	}
+}
+
+func TestCancelStopsTransition(t *testing.T) {
+	canvas := display.NewCanvas(8, 8)
+	tr := transition.Start(canvas, 0, 0, bits.NewMatrix(8, 8), filled(8, 8), transition.Wipe(transition.Right), time.Hour)
+	tr.Cancel()
+
+	select {
+	case <-tr.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Transition did not finish after Cancel")
+	}
+	if tr.Wait() {
+		t.Error("Cancelled transition reported as completed")
+	}
+}
@@ -0,0 +1,215 @@
+package viewport_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/realency/arke/pkg/arketest"
+	"github.com/realency/arke/pkg/bits"
+	"github.com/realency/arke/pkg/display"
+	"github.com/realency/arke/pkg/viewport"
+)
+
+// blockingViewPort is a ViewPort whose Attach does not return until released.
+type blockingViewPort struct {
+	*arketest.ViewPort
+	release chan struct{}
+}
+
+func (b *blockingViewPort) Attach(canvas *display.Canvas, row, col int) {
+	<-b.release
+	b.ViewPort.Attach(canvas, row, col)
+}
+
+// dimmableViewPort is a ViewPort supporting brightness, whose SetBrightness does not return until released,
+// if given a channel to release it.
+type dimmableViewPort struct {
+	*arketest.ViewPort
+	release    chan struct{}
+	mutex      sync.Mutex
+	brightness byte
+}
+
+func (d *dimmableViewPort) SetBrightness(bright byte) {
+	if d.release != nil {
+		<-d.release
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.brightness = bright
+}
+
+func (d *dimmableViewPort) level() byte {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.brightness
+}
+
+// eventually polls a condition until it holds, failing the test if it does not hold within a second.
+func eventually(t *testing.T, condition func() bool, message string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal(message)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBroadcastAttachesAllChildren(t *testing.T) {
+	a := arketest.NewViewPort(8, 32)
+	b := arketest.NewViewPort(8, 32)
+	bc := viewport.NewBroadcast()
+	bc.Add(a)
+	bc.Add(b)
+
+	canvas := display.NewCanvas(16, 64)
+	bc.Attach(canvas, 4, 8)
+	for _, f := range []*arketest.ViewPort{a, b} {
+		f := f
+		eventually(t, func() bool {
+			row, col := f.Offset()
+			return f.Canvas() == canvas && row == 4 && col == 8
+		}, "Child not attached at expected location")
+	}
+
+	bc.Detach()
+	eventually(t, func() bool { return a.Canvas() == nil && b.Canvas() == nil }, "Children not detached")
+}
+
+func TestBroadcastIsNotBlockedBySlowChild(t *testing.T) {
+	slow := &blockingViewPort{arketest.NewViewPort(8, 8), make(chan struct{})}
+	defer close(slow.release)
+	fast := arketest.NewViewPort(8, 8)
+
+	bc := viewport.NewBroadcast()
+	bc.Add(slow)
+	bc.Add(fast)
+
+	canvas := display.NewCanvas(8, 8)
+	bc.Attach(canvas, 0, 0)
+	eventually(t, func() bool { return fast.Canvas() == canvas }, "Fast child not attached while slow child blocked")
+}
+
+func TestBroadcastBrightnessIsNotBlockedBySlowChild(t *testing.T) {
+	slow := &dimmableViewPort{ViewPort: arketest.NewViewPort(8, 8), release: make(chan struct{})}
+	defer close(slow.release)
+	fast := &dimmableViewPort{ViewPort: arketest.NewViewPort(8, 8)}
+
+	bc := viewport.NewBroadcast()
+	bc.Add(slow)
+	bc.Add(fast)
+
+	done := make(chan struct{})
+	go func() {
+		bc.SetBrightness(5)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("SetBrightness blocked by slow child")
+	}
+	eventually(t, func() bool { return fast.level() == 5 }, "Brightness not set on fast child while slow child blocked")
+}
+
+func TestBroadcastAppliesTransformToChild(t *testing.T) {
+	rear := arketest.NewViewPort(8, 16)
+	bc := viewport.NewBroadcast()
+	bc.AddTransformed(rear, viewport.Transform{MirrorHorizontal: true})
+
+	canvas := display.NewCanvas(8, 16)
+	canvas.Set(2, 0, true)
+	bc.Attach(canvas, 0, 0)
+
+	eventually(t, func() bool {
+		c := rear.Canvas()
+		return c != nil && c.Get(2, 15) && !c.Get(2, 0)
+	}, "Mirrored content not shown on child")
+}
+
+func TestTransformRotatesClockwise(t *testing.T) {
+	m := bits.NewMatrix(2, 3)
+	m.Set(0, 0, true)
+
+	r := viewport.Transform{Rotation: viewport.Rotate90}.Apply(m)
+	if h, w := r.Size(); h != 3 || w != 2 {
+		t.Fatalf("Rotated size is %dx%d, expected 3x2", h, w)
+	}
+	if !r.Get(0, 1) {
+		t.Error("Top-left pixel not rotated to top-right")
+	}
+}
+
+func TestFlushWaitsForChildrenToBeAttached(t *testing.T) {
+	b := viewport.NewBroadcast()
+	children := []*arketest.ViewPort{arketest.NewViewPort(8, 8), arketest.NewViewPort(8, 8)}
+	for _, c := range children {
+		b.Add(c)
+	}
+	canvas := display.NewCanvas(8, 32)
+	b.Attach(canvas, 0, 4)
+
+	if err := b.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range children {
+		if c.Canvas() != canvas {
+			t.Errorf("Child %d not attached after Flush", i)
+		}
+	}
+}
+
+func TestBroadcastRemoveDetachesChild(t *testing.T) {
+	a := arketest.NewViewPort(8, 32)
+	b := arketest.NewViewPort(8, 16)
+	bc := viewport.NewBroadcast()
+	bc.Add(a)
+	bc.AddTransformed(b, viewport.Transform{MirrorHorizontal: true})
+
+	canvas := display.NewCanvas(16, 64)
+	bc.Attach(canvas, 0, 0)
+	if !bc.Remove(a) {
+		t.Fatal("Remove did not find child")
+	}
+	if a.Canvas() != nil {
+		t.Error("Removed child still attached")
+	}
+	if h, w := bc.Size(); h != 8 || w != 16 {
+		t.Errorf("Broadcast size is %dx%d after Remove, expected 8x16", h, w)
+	}
+	if bc.Remove(a) {
+		t.Error("Remove found child that was already removed")
+	}
+
+	if !bc.Remove(b) {
+		t.Fatal("Remove did not find transformed child")
+	}
+	if b.Canvas() != nil {
+		t.Error("Removed transformed child still attached")
+	}
+}
+
+func TestBroadcastAddClampsLocation(t *testing.T) {
+	a := arketest.NewViewPort(8, 8)
+	bc := viewport.NewBroadcast()
+	bc.Add(a)
+
+	canvas := display.NewCanvas(16, 16)
+	bc.Attach(canvas, 8, 8)
+	b := arketest.NewViewPort(16, 16)
+	bc.Add(b)
+	if row, col := bc.Offset(); row != 0 || col != 0 {
+		t.Errorf("Broadcast located at %d,%d after Add, expected 0,0", row, col)
+	}
+	for _, f := range []*arketest.ViewPort{a, b} {
+		f := f
+		eventually(t, func() bool {
+			row, col := f.Offset()
+			return f.Canvas() == canvas && row == 0 && col == 0
+		}, "Child not moved to clamped location")
+	}
+}
@@ -0,0 +1,165 @@
+package viewport_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/realency/arke/pkg/arketest"
+	"github.com/realency/arke/pkg/clock"
+	"github.com/realency/arke/pkg/display"
+	"github.com/realency/arke/pkg/viewport"
+)
+
+// Returns a new camera timed by a fake clock.
+func newCamera(vp viewport.ViewPort) (*viewport.Camera, *arketest.Clock) {
+	fake := arketest.NewClock(time.Now())
+	return viewport.NewCamera(vp, clock.With(fake)), fake
+}
+
+// Advances a fake clock by a frame, once the camera is waiting for it.
+func nextFrame(fake *arketest.Clock) {
+	fake.BlockUntil(1)
+	fake.Advance(viewport.CameraFrameInterval)
+}
+
+func wait(t *testing.T, done <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Movement did not finish")
+	}
+}
+
+func TestEasingsStartAndFinishAtRest(t *testing.T) {
+	for name, e := range map[string]viewport.Easing{
+		"Linear":    viewport.Linear,
+		"EaseInOut": viewport.EaseInOut,
+		"Bounce":    viewport.Bounce,
+	} {
+		if math.Abs(e(0)) > 1e-9 || math.Abs(e(1)-1) > 1e-9 {
+			t.Errorf("%s maps 0 to %v and 1 to %v", name, e(0), e(1))
+		}
+	}
+}
+
+func TestMoveToClampsToCanvas(t *testing.T) {
+	vp := arketest.NewViewPort(8, 8)
+	vp.Attach(display.NewCanvas(16, 64), 0, 0)
+	c, fake := newCamera(vp)
+
+	done := c.MoveTo(100, 30, 20*time.Millisecond, viewport.EaseInOut)
+	nextFrame(fake)
+	wait(t, done)
+	if row, col := c.Position(); row != 8 || col != 30 {
+		t.Errorf("Camera at %d,%d, expected 8,30", row, col)
+	}
+	if row, col := vp.Offset(); row != 8 || col != 30 {
+		t.Errorf("ViewPort at %d,%d, expected 8,30", row, col)
+	}
+}
+
+func TestMoveAlongFinishesAtLastWaypoint(t *testing.T) {
+	vp := arketest.NewViewPort(8, 8)
+	vp.Attach(display.NewCanvas(32, 32), 0, 0)
+	c, fake := newCamera(vp)
+
+	path := []viewport.Point{{Row: 0, Col: 24}, {Row: 24, Col: 24}, {Row: 12, Col: 0}}
+	done := c.MoveAlong(path, 20*time.Millisecond, nil)
+	nextFrame(fake)
+	wait(t, done)
+	if row, col := vp.Offset(); row != 12 || col != 0 {
+		t.Errorf("ViewPort at %d,%d, expected 12,0", row, col)
+	}
+}
+
+func TestPanStopsAtEdgeWhenClamped(t *testing.T) {
+	vp := arketest.NewViewPort(8, 8)
+	vp.Attach(display.NewCanvas(8, 64), 0, 0)
+	c, fake := newCamera(vp)
+
+	done := c.Pan(0, 2000)
+	nextFrame(fake)
+	wait(t, done)
+	if row, col := vp.Offset(); row != 0 || col != 56 {
+		t.Errorf("ViewPort at %d,%d, expected 0,56", row, col)
+	}
+}
+
+func TestCameraWrapsAroundCanvas(t *testing.T) {
+	vp := viewport.NewTransformed(arketest.NewViewPort(8, 8), viewport.Transform{Wrap: true})
+	vp.Attach(display.NewCanvas(8, 64), 0, 0)
+	c := viewport.NewCamera(vp)
+	c.SetWrap(true)
+
+	c.Jump(0, 70)
+	if row, col := vp.Offset(); row != 0 || col != 6 {
+		t.Errorf("ViewPort at %d,%d, expected 0,6", row, col)
+	}
+	c.Jump(-2, -1)
+	if row, col := vp.Offset(); row != 6 || col != 63 {
+		t.Errorf("ViewPort at %d,%d, expected 6,63", row, col)
+	}
+}
+
+func TestFollowCentresTarget(t *testing.T) {
+	vp := arketest.NewViewPort(8, 8)
+	vp.Attach(display.NewCanvas(32, 64), 0, 0)
+	c, fake := newCamera(vp)
+
+	done := c.Follow(func() (int, int) { return 12, 40 }, 0.5)
+	for i := 0; ; i++ {
+		if row, col := vp.Offset(); row == 8 && col == 36 {
+			break
+		}
+		if i == 20 {
+			t.Fatal("Target not centred")
+		}
+		nextFrame(fake)
+		fake.BlockUntil(1)
+	}
+	c.Stop()
+	wait(t, done)
+}
+
+func TestCameraIsTimedByItsClock(t *testing.T) {
+	vp := arketest.NewViewPort(8, 8)
+	vp.Attach(display.NewCanvas(8, 64), 0, 0)
+	c, fake := newCamera(vp)
+
+	done := c.MoveTo(0, 40, 4*viewport.CameraFrameInterval, viewport.Linear)
+	for _, expected := range []int{10, 20, 30} {
+		nextFrame(fake)
+		fake.BlockUntil(1)
+		if _, col := vp.Offset(); col != expected {
+			t.Errorf("ViewPort at column %d, expected %d", col, expected)
+		}
+	}
+	nextFrame(fake)
+	wait(t, done)
+	if _, col := vp.Offset(); col != 40 {
+		t.Errorf("ViewPort at column %d, expected 40", col)
+	}
+}
+
+func TestWrappingTransformedShowsContentAcrossEdge(t *testing.T) {
+	inner := arketest.NewViewPort(1, 4)
+	vp := viewport.NewTransformed(inner, viewport.Transform{Wrap: true})
+	defer vp.Close()
+
+	canvas := display.NewCanvas(1, 8)
+	vp.Attach(canvas, 0, 6)
+	canvas.Set(0, 0, true)
+	if err := vp.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if row, col := vp.Offset(); row != 0 || col != 6 {
+		t.Fatalf("Located at %d,%d, expected 0,6", row, col)
+	}
+	if proxy := inner.Canvas(); proxy == nil || !proxy.Get(0, 2) {
+		t.Error("Content at the left edge of the canvas not shown after the right edge")
+	}
+}
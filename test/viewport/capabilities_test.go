@@ -0,0 +1,108 @@
+package viewport_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/realency/arke/pkg/arketest"
+	"github.com/realency/arke/pkg/display"
+	"github.com/realency/arke/pkg/viewport"
+)
+
+// closingViewPort is a poweredViewPort that can be closed, and reports an error.
+type closingViewPort struct {
+	*poweredViewPort
+	closed bool
+	err    error
+}
+
+func (c *closingViewPort) Close() error {
+	c.closed = true
+	return c.err
+}
+
+func (c *closingViewPort) Err() error {
+	return c.err
+}
+
+func TestHelpersReportUnsupportedCapabilities(t *testing.T) {
+	vp := arketest.NewViewPort(8, 8)
+	vp.Attach(display.NewCanvas(8, 8), 0, 0)
+
+	if viewport.SetBrightness(vp, 3) || viewport.Sleep(vp) || viewport.Wake(vp) || viewport.Asleep(vp) {
+		t.Error("Helper reported support for a capability the viewport lacks")
+	}
+	if err := viewport.Close(vp); err != nil || vp.Canvas() != nil {
+		t.Errorf("Close did not detach the viewport: %v", err)
+	}
+	if err := viewport.Err(vp); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestCompositeForwardsCapabilitiesToChildren(t *testing.T) {
+	failure := errors.New("write failed")
+	left := &closingViewPort{poweredViewPort: &poweredViewPort{ViewPort: arketest.NewViewPort(8, 32)}}
+	right := &closingViewPort{poweredViewPort: &poweredViewPort{ViewPort: arketest.NewViewPort(8, 32)}, err: failure}
+
+	c := viewport.NewComposite()
+	c.Add(left, 0, 0)
+	c.Add(right, 0, 32)
+
+	if !viewport.SetBrightness(c, 5) || left.brightness != 5 || right.brightness != 5 {
+		t.Error("Brightness not forwarded to children")
+	}
+	viewport.Sleep(c)
+	if !c.Asleep() || !left.asleep || !right.asleep {
+		t.Error("Sleep not forwarded to children")
+	}
+	viewport.Wake(c)
+	if c.Asleep() || left.asleep || right.asleep {
+		t.Error("Wake not forwarded to children")
+	}
+	if err := viewport.Err(c); err != failure {
+		t.Errorf("Err returned %v, expected %v", err, failure)
+	}
+	if err := viewport.Close(c); err != failure || !left.closed || !right.closed {
+		t.Errorf("Close not forwarded to children: %v", err)
+	}
+}
+
+func TestCombinationsSupportOnlyWhatEveryChildSupports(t *testing.T) {
+	plain := viewport.NewComposite()
+	plain.Add(arketest.NewViewPort(8, 32), 0, 0)
+	plain.Add(arketest.NewViewPort(8, 32), 0, 32)
+
+	powered := &poweredViewPort{ViewPort: arketest.NewViewPort(8, 32)}
+	mixed := viewport.NewBroadcast()
+	mixed.Add(powered)
+	mixed.Add(arketest.NewViewPort(8, 32))
+	defer mixed.Close()
+
+	for name, vp := range map[string]viewport.ViewPort{
+		"composite":   plain,
+		"broadcast":   mixed,
+		"transformed": viewport.NewTransformed(plain, viewport.Transform{Invert: true}),
+		"empty":       viewport.NewComposite(),
+	} {
+		if viewport.SupportsBrightness(vp) || viewport.SupportsPower(vp) {
+			t.Errorf("%s reports support for capabilities a child lacks", name)
+		}
+		if viewport.SetBrightness(vp, 3) || viewport.Sleep(vp) || viewport.Wake(vp) || viewport.Asleep(vp) {
+			t.Errorf("Helper reported support for capabilities a child of %s lacks", name)
+		}
+	}
+	if powered.asleep || powered.changes != 0 {
+		t.Error("Helper changed a child of a combination lacking support")
+	}
+
+	// Called directly, the methods of a combination still apply to the children that support them
+	mixed.Sleep()
+	if err := mixed.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !powered.asleep {
+		t.Error("Sleep not forwarded to the child supporting power")
+	}
+}
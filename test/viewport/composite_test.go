@@ -0,0 +1,71 @@
+package viewport_test
+
+import (
+	"testing"
+
+	"github.com/realency/arke/pkg/arketest"
+	"github.com/realency/arke/pkg/display"
+	"github.com/realency/arke/pkg/viewport"
+)
+
+func TestCompositeSizeEnclosesChildren(t *testing.T) {
+	c := viewport.NewComposite()
+	c.Add(arketest.NewViewPort(8, 32), 0, 0)
+	c.Add(arketest.NewViewPort(8, 32), 8, 0)
+	c.Add(arketest.NewViewPort(16, 16), 0, 32)
+
+	if h, w := c.Size(); h != 16 || w != 48 {
+		t.Errorf("Composite size is %dx%d, expected 16x48", h, w)
+	}
+}
+
+func TestCompositeForwardsOffsetsToChildren(t *testing.T) {
+	a := arketest.NewViewPort(8, 32)
+	b := arketest.NewViewPort(8, 32)
+	c := viewport.NewComposite()
+	c.Add(a, 0, 0)
+	c.Add(b, 8, 0)
+
+	canvas := display.NewCanvas(32, 64)
+	c.Attach(canvas, 2, 3)
+	if row, col := b.Offset(); row != 10 || col != 3 {
+		t.Errorf("Child attached at %d,%d, expected 10,3", row, col)
+	}
+	if b.Canvas() != canvas {
+		t.Error("Child not attached to canvas")
+	}
+
+	c.Locate(100, 100)
+	if row, col := c.Offset(); row != 16 || col != 32 {
+		t.Errorf("Composite located at %d,%d, expected 16,32", row, col)
+	}
+	if row, col := b.Offset(); row != 24 || col != 32 {
+		t.Errorf("Child located at %d,%d, expected 24,32", row, col)
+	}
+
+	c.Detach()
+	if a.Canvas() != nil || b.Canvas() != nil {
+		t.Error("Children still attached after Detach")
+	}
+}
+
+func TestCompositeMovesExistingChildrenWhenAddingGrowsIt(t *testing.T) {
+	a := arketest.NewViewPort(8, 32)
+	c := viewport.NewComposite()
+	c.Add(a, 0, 0)
+
+	canvas := display.NewCanvas(16, 32)
+	c.Attach(canvas, 8, 0)
+	b := arketest.NewViewPort(8, 32)
+	c.Add(b, 8, 0)
+
+	if row, col := c.Offset(); row != 0 || col != 0 {
+		t.Errorf("Composite located at %d,%d, expected 0,0", row, col)
+	}
+	if row, col := a.Offset(); row != 0 || col != 0 {
+		t.Errorf("Existing child located at %d,%d, expected 0,0", row, col)
+	}
+	if row, col := b.Offset(); row != 8 || col != 0 || b.Canvas() != canvas {
+		t.Errorf("New child attached at %d,%d, expected 8,0", row, col)
+	}
+}
@@ -0,0 +1,89 @@
+package viewport_test
+
+import (
+	"github.com/realency/arke/pkg/arketest"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/realency/arke/pkg/viewport"
+)
+
+// poweredViewPort is an arketest.ViewPort that supports sleep and brightness, and counts the changes made to it.
+type poweredViewPort struct {
+	*arketest.ViewPort
+	asleep     bool
+	brightness byte
+	changes    int
+}
+
+func (p *poweredViewPort) Sleep()       { p.asleep = true; p.changes++ }
+func (p *poweredViewPort) Wake()        { p.asleep = false; p.changes++ }
+func (p *poweredViewPort) Asleep() bool { return p.asleep }
+
+func (p *poweredViewPort) SetBrightness(bright byte) {
+	p.brightness = bright
+	p.changes++
+}
+
+func at(hour, minute int) time.Time {
+	return time.Date(2024, time.March, 1, hour, minute, 0, 0, time.Local)
+}
+
+func TestPowerScheduleBlanksAndDimsOnSchedule(t *testing.T) {
+	vp := &poweredViewPort{ViewPort: arketest.NewViewPort(8, 32)}
+	s := viewport.NewPowerSchedule(10, vp)
+	s.Blank(23*time.Hour, 6*time.Hour)
+	s.Dim(20*time.Hour, 23*time.Hour, 2)
+
+	steps := []struct {
+		time       time.Time
+		asleep     bool
+		brightness byte
+	}{
+		{at(12, 0), false, 10},
+		{at(21, 30), false, 2},
+		{at(23, 15), true, 2},
+		{at(3, 0), true, 2},
+		{at(6, 0), false, 10},
+	}
+
+	for _, step := range steps {
+		s.Update(step.time)
+		if vp.asleep != step.asleep || vp.brightness != step.brightness {
+			t.Errorf("At %s: asleep %v, brightness %d; expected %v, %d",
+				step.time.Format("15:04"), vp.asleep, vp.brightness, step.asleep, step.brightness)
+		}
+	}
+}
+
+func TestPowerScheduleChangesDisplaysOnlyWhenPeriodChanges(t *testing.T) {
+	vp := &poweredViewPort{ViewPort: arketest.NewViewPort(8, 32)}
+	s := viewport.NewPowerSchedule(10, vp)
+	s.Blank(23*time.Hour, 6*time.Hour)
+
+	s.Update(at(23, 0))
+	changes := vp.changes
+	s.Update(at(23, 30))
+	s.Update(at(1, 0))
+	if vp.changes != changes {
+		t.Errorf("Made %d changes within one period", vp.changes-changes)
+	}
+}
+
+func TestBlankingPeriodKeepsToWallClockWhenClocksChange(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := viewport.BlankingPeriod{From: 7*time.Hour + 30*time.Minute, To: 8*time.Hour + 30*time.Minute}
+
+	// The clocks go forward an hour at 01:00 on this day, so only seven hours have passed since midnight by 08:00
+	if !p.Contains(time.Date(2026, 3, 29, 8, 0, 0, 0, london)) {
+		t.Error("08:00 on the day the clocks go forward is not within 07:30-08:30")
+	}
+	// The clocks go back an hour at 02:00 on this day, so nine hours have passed since midnight by 08:00
+	if !p.Contains(time.Date(2026, 10, 25, 8, 0, 0, 0, london)) {
+		t.Error("08:00 on the day the clocks go back is not within 07:30-08:30")
+	}
+}
@@ -0,0 +1,92 @@
+package viewport_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/realency/arke/pkg/arketest"
+	"github.com/realency/arke/pkg/bits"
+	"github.com/realency/arke/pkg/display"
+	"github.com/realency/arke/pkg/viewport"
+)
+
+func TestTransformInvertsAfterRotating(t *testing.T) {
+	m := bits.NewMatrix(2, 3)
+	m.Set(0, 0, true)
+
+	r := viewport.Transform{Rotation: viewport.Rotate180, Invert: true}.Apply(m)
+	for row := 0; row < 2; row++ {
+		for col := 0; col < 3; col++ {
+			expected := row != 1 || col != 2
+			if r.Get(row, col) != expected {
+				t.Errorf("Pixel %d,%d is %v, expected %v", row, col, r.Get(row, col), expected)
+			}
+		}
+	}
+}
+
+func TestTransformedReportsRotatedSize(t *testing.T) {
+	inner := arketest.NewViewPort(8, 32)
+	vp := viewport.NewTransformed(inner, viewport.Transform{Rotation: viewport.Rotate90})
+	defer vp.Close()
+
+	if h, w := vp.Size(); h != 32 || w != 8 {
+		t.Fatalf("Size is %dx%d, expected 32x8", h, w)
+	}
+
+	canvas := display.NewCanvas(40, 40)
+	vp.Attach(canvas, 0, 0)
+	vp.Locate(20, 35)
+	if row, col := vp.Offset(); row != 8 || col != 32 {
+		t.Errorf("Located at %d,%d, expected 8,32", row, col)
+	}
+}
+
+func TestTransformedShowsTransformedContent(t *testing.T) {
+	inner := arketest.NewViewPort(4, 8)
+	vp := viewport.NewTransformed(inner, viewport.Transform{Rotation: viewport.Rotate180, Invert: true})
+	defer vp.Close()
+
+	canvas := display.NewCanvas(4, 8)
+	vp.Attach(canvas, 0, 0)
+	canvas.Set(0, 0, true)
+	if err := vp.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	proxy := inner.Canvas()
+	if proxy == nil || proxy == canvas {
+		t.Fatal("Wrapped ViewPort not attached to its own canvas")
+	}
+	if proxy.Get(3, 7) || !proxy.Get(0, 0) {
+		t.Error("Content not rotated and inverted")
+	}
+
+	vp.Detach()
+	if inner.Canvas() != nil {
+		t.Error("Wrapped ViewPort not detached")
+	}
+}
+
+func TestTransformedIgnoresSnapshotsOfPreviousCanvas(t *testing.T) {
+	inner := arketest.NewViewPort(4, 8)
+	vp := viewport.NewTransformed(inner, viewport.Transform{})
+	defer vp.Close()
+
+	// Snapshots of the old canvas may still be queued as the blank canvas, of the same size, is attached
+	old := display.NewCanvas(4, 8)
+	blank := display.NewCanvas(4, 8)
+	for i := 0; i < 100; i++ {
+		vp.Attach(old, 0, 0)
+		for col := 0; col < 8; col++ {
+			old.Set(i%4, col, true)
+		}
+		vp.Attach(blank, 0, 0)
+		if err := vp.Flush(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if got := inner.Canvas().Matrix().String(); got != blank.Matrix().String() {
+			t.Fatalf("Snapshot of previous canvas shown after attaching another:\n%s", got)
+		}
+	}
+}
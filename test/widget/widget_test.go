@@ -0,0 +1,142 @@
+package widget_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/realency/arke/pkg/bits"
+	"github.com/realency/arke/pkg/display"
+	"github.com/realency/arke/pkg/widget"
+)
+
+// blockFont renders every rune as a solid block, two pixels wide and three high.
+func blockFont(r rune) *bits.Matrix {
+	m := bits.NewMatrix(3, 2)
+	m.Not()
+	return m
+}
+
+func countOnes(m *bits.Matrix, row, col, height, width int) int {
+	n := 0
+	for i := row; i < row+height; i++ {
+		for j := col; j < col+width; j++ {
+			if m.Get(i, j) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestLabelDrawsTextWithinBounds(t *testing.T) {
+	canvas := display.NewCanvas(8, 16)
+	l := widget.NewLabel(blockFont, "ab")
+	l.Mount(canvas, widget.Rect{Row: 2, Col: 4, Height: 3, Width: 8})
+
+	m := canvas.Matrix()
+	if n := countOnes(m, 2, 4, 3, 4); n != 12 {
+		t.Errorf("Label text has %d one-bits, expected 12", n)
+	}
+	if n := countOnes(m, 0, 0, 8, 16); n != 12 {
+		t.Errorf("Canvas has %d one-bits, expected 12", n)
+	}
+}
+
+func TestLabelRedrawsOnlyWhenTextChanges(t *testing.T) {
+	canvas := display.NewCanvas(8, 16)
+	updates := make(chan *bits.Matrix, 10)
+	canvas.AddObserver(updates)
+
+	l := widget.NewLabel(blockFont, "a")
+	l.Mount(canvas, widget.Rect{Height: 3, Width: 16})
+	l.SetText("a")
+	l.SetText("abc")
+
+	if n := len(updates); n != 2 {
+		t.Errorf("Canvas notified %d times, expected 2", n)
+	}
+}
+
+func TestGridMountsChildrenInCells(t *testing.T) {
+	canvas := display.NewCanvas(8, 16)
+	g := widget.NewGrid(2, 2)
+	a := widget.NewIndicator()
+	b := widget.NewIndicator()
+	g.Add(a, 0, 0)
+	g.AddSpan(b, 1, 0, 1, 2)
+	g.Mount(canvas, widget.Rect{Height: 8, Width: 16})
+
+	if r := a.Bounds(); r != (widget.Rect{Row: 0, Col: 0, Height: 4, Width: 8}) {
+		t.Errorf("Unexpected bounds for first child: %+v", r)
+	}
+	if r := b.Bounds(); r != (widget.Rect{Row: 4, Col: 0, Height: 4, Width: 16}) {
+		t.Errorf("Unexpected bounds for second child: %+v", r)
+	}
+
+	b.SetOn(true)
+	if n := countOnes(canvas.Matrix(), 0, 0, 8, 16); n != 64 {
+		t.Errorf("Canvas has %d one-bits, expected 64", n)
+	}
+}
+
+func TestProgressBarFillsProportionOfInterior(t *testing.T) {
+	canvas := display.NewCanvas(4, 12)
+	p := widget.NewProgressBar()
+	p.Mount(canvas, widget.Rect{Height: 4, Width: 12})
+	p.SetValue(0.5)
+
+	if n := countOnes(canvas.Matrix(), 1, 1, 2, 10); n != 10 {
+		t.Errorf("Progress bar interior has %d one-bits, expected 10", n)
+	}
+}
+
+func TestSparklineKeepsOnlyAsManyValuesAsColumns(t *testing.T) {
+	canvas := display.NewCanvas(4, 4)
+	s := widget.NewSparkline()
+	s.Mount(canvas, widget.Rect{Height: 4, Width: 4})
+	for i := 0; i < 10; i++ {
+		s.Push(float64(i))
+	}
+
+	if n := len(s.Values()); n != 4 {
+		t.Errorf("Sparkline holds %d values, expected 4", n)
+	}
+	if !canvas.Get(0, 3) || !canvas.Get(3, 0) {
+		t.Error("Sparkline does not span from bottom-left to top-right")
+	}
+}
+
+func TestSparklineLeavesNonFiniteValuesBlank(t *testing.T) {
+	canvas := display.NewCanvas(4, 4)
+	s := widget.NewSparkline()
+	s.Mount(canvas, widget.Rect{Height: 4, Width: 4})
+	s.Push(0)
+	s.Push(math.NaN())
+	s.Push(math.Inf(1))
+	s.Push(3)
+
+	if n := countOnes(canvas.Matrix(), 0, 1, 4, 2); n != 0 {
+		t.Errorf("Non-finite values drew %d one-bits, expected none", n)
+	}
+	if !canvas.Get(0, 3) || !canvas.Get(3, 0) {
+		t.Error("Sparkline does not scale finite values from bottom-left to top-right")
+	}
+}
+
+func TestIndicatorStaysLitWhenBlinkingStops(t *testing.T) {
+	canvas := display.NewCanvas(2, 2)
+	d := widget.NewIndicator()
+	d.Mount(canvas, widget.Rect{Height: 2, Width: 2})
+	d.SetOn(true)
+
+	for i := 0; i < 100; i++ {
+		d.SetBlinking(time.Microsecond)
+		time.Sleep(10 * time.Microsecond)
+		d.SetBlinking(0)
+		time.Sleep(10 * time.Microsecond)
+		if n := countOnes(canvas.Matrix(), 0, 0, 2, 2); n != 4 {
+			t.Fatalf("Indicator has %d one-bits after blinking stopped, expected 4", n)
+		}
+	}
+}